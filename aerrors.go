@@ -1,30 +1,72 @@
 package aerrors
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
 )
 
 var errorPool = &sync.Pool{
 	New: func() interface{} {
-		return &AError{
-			buf: make([]byte, 0, 500),
-		}
+		return allocAError()
 	},
 }
 
+// poolingDisabled is set by SetPooling(false); AErrors are then always freshly
+// allocated and Release only resets them.
+var poolingDisabled atomic.Bool
+
+// SetPooling enables or disables the reuse of released AErrors.
+//
+// Pooling is enabled by default. Disabling it trades allocations for the
+// guarantee that an AError is never handed out twice, which can be useful
+// while hunting a missing or premature Release.
+func SetPooling(enabled bool) {
+	poolingDisabled.Store(!enabled)
+}
+
+// allocAError allocates an AError; the program counters of its stack are only allocated
+// when it takes one.
+func allocAError() *AError {
+	e := &AError{buf: make([]byte, 0, 500)}
+	e.id = e.idBuf[:0]
+	return e
+}
+
 func newAError(code Code, reason string) *AError {
-	e := errorPool.Get().(*AError)
-	e.buf = e.buf[:0]
+	var e *AError
+	if poolingDisabled.Load() {
+		e = allocAError()
+	} else {
+		e = errorPool.Get().(*AError)
+	}
+	e.released = false
 	e.withCode(code).withReason(reason)
 	return e
 }
 
 func putEvent(e *AError) {
+	if poolingDisabled.Load() {
+		return
+	}
 	errorPool.Put(e)
 }
 
+// Release hands err back to the pool when it is an *AError; other errors are ignored.
+//
+// See (*AError).Release for the rules that apply after releasing.
+func Release(err error) {
+	if e, ok := err.(*AError); ok {
+		e.Release()
+	}
+}
+
 type TypeCoder interface {
 	TypeCode() string
 }
@@ -52,32 +94,86 @@ type Error interface {
 
 type Builder interface {
 	WithParent(parent error) Builder
+	WithParents(parents ...error) Builder
 	WithMessage(message string) Builder
+	WithMessageKey(key string, args ...any) Builder
+	WithField(key string, value any) Builder
+	WithFields(fields map[string]any) Builder
 	WithStack() Builder
+	WithStackOptions(opts ...StackOption) Builder
+	WithContext(ctx context.Context) Builder
+	WithFieldViolation(field, description string) Builder
+	WithRetryDelay(delay time.Duration) Builder
+	WithQuotaViolation(subject, description string) Builder
+	WithPreconditionViolation(typ, subject, description string) Builder
+	WithResourceInfo(resourceType, resourceName, owner, description string) Builder
+	WithHelpLink(description, url string) Builder
+	WithDebugInfo(detail string) Builder
 	Err() Error
 	withCode(code Code) Builder
 	withReason(reason string) Builder
 }
 
+// AError is the error built by New and the other constructors.
+//
+// The accessors of a nil *AError report no error, the same way the package functions do
+// for a nil error.
 type AError struct {
-	parent  error
+	parents []error
+	fields  []field
 	code    Code
 	reason  string
 	message string
-	stack   string
-	buf     []byte
+	// messageKey and messageArgs localize the message, see WithMessageKey
+	messageKey  string
+	messageArgs []any
+	pcs         []uintptr
+	frames      []Frame
+	// stackCfg is the configuration the stack was captured with
+	stackCfg *stackConfig
+	// policy is the StackPolicy from the context given to WithContext
+	policy StackPolicy
+	// def is the Definition the error was created from
+	def *Definition
+	// details are the google.rpc details of the error
+	details []proto.Message
+	// httpCode and grpcCode, when httpCode is not zero, are the statuses received along
+	// with a code this process does not register
+	httpCode int
+	grpcCode codes.Code
+	id       []byte
+	// idBuf holds the ULIDs and UUIDs set as id without allocating
+	idBuf [36]byte
+	buf   []byte
+
+	released bool
 }
 
 func New(code Code, reason string) Builder {
 	return newAError(code, reason)
 }
 
+// WithParent adds parent as a cause of the error; nil parents are ignored.
 func (err *AError) WithParent(parent error) Builder {
 	if err == nil {
 		return nil
 	}
-	err.parent = parent
-	err.buf = err.appendString(err.appendKey(err.buf, "parent"), parent.Error())
+	err.assertLive()
+	err.addParent(parent)
+	return err
+}
+
+// WithParents adds every non-nil parent as a cause of the error.
+//
+// Causes are rendered by Error() in the order they were added.
+func (err *AError) WithParents(parents ...error) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	for _, parent := range parents {
+		err.addParent(parent)
+	}
 	return err
 }
 
@@ -85,53 +181,192 @@ func (err *AError) WithMessage(message string) Builder {
 	if err == nil {
 		return nil
 	}
+	err.assertLive()
 	err.message = message
-	err.buf = err.appendString(err.appendKey(err.buf, "message"), message)
 	return err
 }
 
+// WithStack captures the stack of the caller as allowed by the active StackPolicy.
 func (err *AError) WithStack() Builder {
 	if err == nil {
 		return nil
 	}
-	const depth = 32
-	err.stack = LogStack(2, depth)
+	err.assertLive()
+	err.captureStack(1, nil)
+	return err
+}
+
+// WithStackOptions captures the stack with the given options applied on top of the
+// ones set with SetStackOptions.
+func (err *AError) WithStackOptions(opts ...StackOption) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	err.captureStack(1, opts)
 	return err
 }
 
+// Err finalizes the builder and returns the resulting error with a newly generated ID.
+//
+// The returned error stays valid until it is released with Release; errors that
+// are never released are simply garbage collected.
 func (err *AError) Err() Error {
 	if err == nil {
 		return nil
 	}
-	err.buf = err.appendString(err.appendLineBreak(err.buf), err.stack)
-	putEvent(err)
+	err.assertLive()
+	err.generateID()
+	err.render()
 	return err
 }
 
+// Release resets err and returns it to the pool so a later New can reuse it.
+//
+// Release is optional. Once called, err and every string obtained from it must
+// no longer be used: the memory will be overwritten by the next error. Build with
+// the aerrors_debug tag to poison released errors and panic on any further use.
+//
+// Releasing an error again does nothing, or panics with the aerrors_debug tag.
+func (err *AError) Release() {
+	if err == nil {
+		return
+	}
+	err.assertLive()
+	if err.released {
+		// putting it back again would hand the same error out to two later New calls
+		return
+	}
+	err.reset()
+	err.released = true
+	if debugRelease {
+		// poisoned errors never go back to the pool, so every later use panics
+		return
+	}
+	putEvent(err)
+}
+
+// Error returns the error as "key:value" pairs, followed by the stack on the next lines
+// when the error has one.
+//
 // nolint
 func (err AError) Error() string {
-	return BytesToString(err.buf)
+	err.assertLive()
+	if !err.hasStack() {
+		return BytesToString(err.buf)
+	}
+	// the filters may leave no frame of the stack
+	frames := err.StackTrace()
+	if len(frames) == 0 {
+		return BytesToString(err.buf)
+	}
+	b := make([]byte, 0, len(err.buf)+1024)
+	b = err.appendLineBreak(append(b, err.buf...))
+	return BytesToString(appendFrames(b, frames))
+}
+
+// Code returns the code of the error.
+func (err *AError) Code() Code {
+	if err == nil {
+		return ErrOK
+	}
+	err.assertLive()
+	return err.code
+}
+
+// TypeCode returns the code of the error as a string.
+func (err *AError) TypeCode() string {
+	if err == nil {
+		return ErrOK.TypeCode()
+	}
+	err.assertLive()
+	return err.code.TypeCode()
+}
+
+// Reason returns the reason of the error.
+func (err *AError) Reason() string {
+	if err == nil {
+		return ""
+	}
+	err.assertLive()
+	return err.reason
+}
+
+// Message returns the message of the error.
+func (err *AError) Message() string {
+	if err == nil {
+		return ""
+	}
+	err.assertLive()
+	return err.message
+}
+
+// Unwrap returns the cause of the error.
+//
+// When the error has several causes the returned error implements Unwrap() []error,
+// the same way errors.Join does, so errors.Is and errors.As visit all of them.
+func (err *AError) Unwrap() error {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	switch len(err.parents) {
+	case 0:
+		return nil
+	case 1:
+		return err.parents[0]
+	default:
+		return causes(err.parents)
+	}
 }
 
+// Causes returns the causes of the error in the order they were added.
+func (err *AError) Causes() []error {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	return err.parents
+}
+
+// Is reports whether target matches the error, for errors.Is.
+//
+// A Code target matches when the code of the error is in its family, and a Sentinel
+// target when the reason is equal as well. A Definition target matches the errors created
+// from it. An *AError target matches on its code family, reason and causes, each of them
+// only when set.
 func (err *AError) Is(target error) bool {
+	if err == nil {
+		return false
+	}
+	err.assertLive()
+	if matched, ok := matchCode(err.code, err.reason, err.def, target); ok {
+		return matched
+	}
 	t, ok := target.(*AError)
 	if !ok {
 		return false
 	}
-	if t.code != ErrOK && t.code != err.code {
+	if t.code != ErrOK && !SameFamily(t.code, err.code) {
 		return false
 	}
 	if t.reason != "" && t.reason != err.reason {
 		return false
 	}
-	if t.parent != nil && !errors.Is(err.parent, t.parent) {
-		return false
+	for _, parent := range t.parents {
+		if !err.hasCause(parent) {
+			return false
+		}
 	}
 
 	return true
 }
 
 func (err *AError) As(target interface{}) bool {
+	if err == nil {
+		return false
+	}
+	err.assertLive()
 	_, ok := target.(**AError)
 	if !ok {
 		return false
@@ -142,16 +377,96 @@ func (err *AError) As(target interface{}) bool {
 
 func (err *AError) withCode(code Code) Builder {
 	err.code = code
-	err.buf = err.appendString(err.appendKey(err.buf, "code"), code.Error())
 	return err
 }
 
 func (err *AError) withReason(reason string) Builder {
 	err.reason = reason
-	err.buf = err.appendString(err.appendKey(err.buf, "reason"), reason)
 	return err
 }
 
+func (err *AError) addParent(parent error) {
+	if parent == nil {
+		return
+	}
+	err.parents = append(err.parents, parent)
+	// the stack of an AError parent is carried over instead of being
+	// rendered in the middle of the line
+	if p, ok := parent.(*AError); ok && !err.hasStack() {
+		err.inheritStack(p)
+	}
+}
+
+// render writes the error into buf as "key:value" pairs.
+func (err *AError) render() {
+	buf := err.appendString(err.appendKey(err.buf[:0], "code"), err.code.Error())
+	buf = err.appendString(err.appendKey(buf, "reason"), err.reason)
+	if err.message != "" {
+		buf = err.appendString(err.appendKey(buf, "message"), err.message)
+	}
+	if len(err.id) != 0 {
+		buf = append(err.appendKey(buf, "id"), err.id...)
+	}
+	for _, parent := range err.parents {
+		if p, ok := parent.(*AError); ok {
+			buf = append(err.appendKey(buf, "parent"), p.line()...)
+			continue
+		}
+		buf = err.appendString(err.appendKey(buf, "parent"), parent.Error())
+	}
+	if len(err.fields) != 0 {
+		buf = err.appendFields(err.appendKey(buf, "fields"))
+	}
+	err.buf = buf
+}
+
+// line returns the rendered error without its stack.
+func (err *AError) line() []byte {
+	return err.buf
+}
+
+// hasCause reports whether any cause of the error matches target.
+func (err *AError) hasCause(target error) bool {
+	for _, parent := range err.parents {
+		if errors.Is(parent, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (err *AError) reset() {
+	clear(err.parents)
+	err.parents = err.parents[:0]
+	err.code = ""
+	err.reason = ""
+	err.message = ""
+	err.messageKey = ""
+	clear(err.messageArgs)
+	err.messageArgs = err.messageArgs[:0]
+	err.pcs = err.pcs[:0]
+	err.frames = nil
+	err.stackCfg = nil
+	err.policy = nil
+	err.def = nil
+	err.httpCode = 0
+	err.grpcCode = codes.OK
+	clear(err.details)
+	err.details = err.details[:0]
+	err.id = err.id[:0]
+	clear(err.fields)
+	err.fields = err.fields[:0]
+	err.buf = err.buf[:0]
+}
+
+func (err *AError) assertLive() {
+	if debugRelease && err.released {
+		panic("aerrors: use of released AError")
+	}
+}
+
+// TypeCode returns the code of the given error as a string, or the one of the code the
+// classifiers map it onto when it has none.
 func TypeCode(err error) string {
 	if err == nil {
 		return ErrOK.TypeCode()
@@ -160,7 +475,47 @@ func TypeCode(err error) string {
 	if errors.As(err, &e) {
 		return e.TypeCode()
 	}
-	return ErrUnknown.TypeCode()
+	code, _, _ := Classify(err)
+	return code.TypeCode()
+}
+
+// walk calls fn for err and every error in its chain, depth first, until fn returns true.
+func walk(err error, fn func(error) bool) bool {
+	if err == nil {
+		return false
+	}
+	if fn(err) {
+		return true
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, err := range u.Unwrap() {
+			if walk(err, fn) {
+				return true
+			}
+		}
+	case interface{ Unwrap() error }:
+		return walk(u.Unwrap(), fn)
+	}
+	return false
+}
+
+// causes exposes the parents of an AError through Unwrap() []error.
+type causes []error
+
+func (c causes) Error() string {
+	b := make([]byte, 0, 64)
+	for i, err := range c {
+		if i > 0 {
+			b = append(b, "; "...)
+		}
+		b = append(b, err.Error()...)
+	}
+	return BytesToString(b)
+}
+
+func (c causes) Unwrap() []error {
+	return c
 }
 
 func (err *AError) appendKey(dst []byte, key string) []byte {
@@ -1,9 +1,22 @@
 package aerrors
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
 	"testing"
+
+	"google.golang.org/grpc/codes"
 )
 
 var (
@@ -18,6 +31,85 @@ func TestNew(t *testing.T) {
 	fmt.Printf("%v\n\n", e)
 }
 
+func TestErrIsNotRecycled(t *testing.T) {
+	first := Internal(fakeReason).WithMessage(fakeMessage).Err()
+	want := first.Error()
+
+	for i := 0; i < 100; i++ {
+		_ = NotFound("other").WithMessage("other").Err()
+	}
+
+	if got := first.Error(); got != want {
+		t.Fatalf("error was overwritten: got %q, want %q", got, want)
+	}
+}
+
+func TestReleaseTwice(t *testing.T) {
+	if debugRelease {
+		t.Skip("released errors are poisoned")
+	}
+	err := Internal(fakeReason).Err()
+	Release(err)
+	Release(err)
+	for i := 0; i < 10; i++ {
+		if first, second := newAError(ErrInternal, ""), newAError(ErrInternal, ""); first == second {
+			t.Fatal("error released twice handed out twice")
+		}
+	}
+}
+
+func TestSetPooling(t *testing.T) {
+	SetPooling(false)
+	defer SetPooling(true)
+
+	first := Internal(fakeReason).Err()
+	Release(first)
+	second := Internal(fakeReason).Err()
+	if first == second {
+		t.Fatal("released error was reused while pooling is disabled")
+	}
+}
+
+type pathError struct{ path string }
+
+func (e *pathError) Error() string { return "bad path " + e.path }
+
+func TestUnwrap(t *testing.T) {
+	errOther := errors.New("other")
+	pe := &pathError{path: "/tmp"}
+
+	single := Internal(fakeReason).WithParent(errExample).Err()
+	if !errors.Is(single, errExample) {
+		t.Error("errors.Is does not find the parent")
+	}
+
+	multi := Internal(fakeReason).WithParents(errExample, pe).WithParent(errOther).Err()
+	for _, target := range []error{errExample, errOther} {
+		if !errors.Is(multi, target) {
+			t.Errorf("errors.Is does not find cause %v", target)
+		}
+	}
+	var got *pathError
+	if !errors.As(multi, &got) || got != pe {
+		t.Error("errors.As does not find the cause type")
+	}
+
+	joined := Internal(fakeReason).WithParent(errors.Join(errExample, errOther)).Err()
+	if !errors.Is(joined, errOther) {
+		t.Error("errors.Is does not walk a joined parent")
+	}
+
+	want := "code:INTERNAL,reason:" + fakeReason + ",id:" + ID(multi) + ",parent:fail,parent:bad path /tmp,parent:other"
+	if multi.Error() != want {
+		t.Errorf("Error() = %q, want %q", multi.Error(), want)
+	}
+
+	target := New(ErrInternal, "").WithParents(errOther, errExample).Err()
+	if !errors.Is(multi, target) {
+		t.Error("AError.Is does not match on all causes")
+	}
+}
+
 func A() error {
 	return B()
 }
@@ -125,3 +217,704 @@ func BenchmarkNewlWithoutStack(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkInternalWithStackReleased(b *testing.B) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := Internal(fakeReason).
+				WithMessage(fakeMessage).
+				WithParent(errExample).
+				WithStack().
+				Err()
+			Release(err)
+		}
+	})
+}
+
+func BenchmarkInternalWithoutStackReleased(b *testing.B) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := Internal(fakeReason).
+				WithMessage(fakeMessage).
+				WithParent(errExample).
+				Err()
+			Release(err)
+		}
+	})
+}
+
+func BenchmarkInternaEmptylWithStackReleased(b *testing.B) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := Internal(fakeReason).
+				WithStack().
+				Err()
+			Release(err)
+		}
+	})
+}
+
+func BenchmarkInternalEmptyWithoutStackReleased(b *testing.B) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := Internal(fakeReason).
+				Err()
+			Release(err)
+		}
+	})
+}
+
+func BenchmarkNewEmptyWithStackReleased(b *testing.B) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := New(ErrInternal, fakeReason).
+				WithStack().
+				Err()
+			Release(err)
+		}
+	})
+}
+
+func BenchmarkNewEmptylWithoutStackReleased(b *testing.B) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := New(ErrInternal, fakeReason).
+				Err()
+			Release(err)
+		}
+	})
+}
+
+func BenchmarkNewWithStackReleased(b *testing.B) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := New(ErrInternal, fakeReason).
+				WithMessage(fakeMessage).
+				WithParent(errExample).
+				WithStack().
+				Err()
+			Release(err)
+		}
+	})
+}
+
+func BenchmarkNewlWithoutStackReleased(b *testing.B) {
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := New(ErrInternal, fakeReason).
+				WithMessage(fakeMessage).
+				WithParent(errExample).
+				Err()
+			Release(err)
+		}
+	})
+}
+
+func TestWrap(t *testing.T) {
+	inner := NotFound(fakeReason).WithStack().Err()
+	wrapped := Wrap(fmt.Errorf("layer: %w", Wrap(inner, "loading user")), "handling request")
+
+	var ae *AError
+	if !errors.As(wrapped, &ae) {
+		t.Fatal("Wrap did not return an AError")
+	}
+	if ae.Code() != ErrNotFound || ae.Reason() != fakeReason || ae.Message() != "handling request" {
+		t.Errorf("unexpected wrapped error: %s", wrapped)
+	}
+	if ae.Stack() != inner.(*AError).Stack() {
+		t.Error("stack was not inherited")
+	}
+	if TypeCode(wrapped) != ErrNotFound.TypeCode() ||
+		HTTPCode(wrapped) != ErrNotFound.HTTPCode() ||
+		GRPCCode(wrapped) != ErrNotFound.GRPCCode() {
+		t.Error("codes changed through wrap layers")
+	}
+	if !errors.Is(wrapped, inner) {
+		t.Error("errors.Is does not find the wrapped error")
+	}
+
+	plain := Wrapf(errExample, "loading %s", "user").(*AError)
+	if plain.Code() != ErrUnknown || plain.Message() != "loading user: fail" || plain.Stack() == "" {
+		t.Errorf("unexpected wrapped plain error: %s", plain)
+	}
+
+	coded := WrapCode(inner, ErrInternal, "lookup failed").(*AError)
+	if coded.Code() != ErrInternal || coded.Reason() != "lookup failed" || coded.Stack() != ae.Stack() {
+		t.Errorf("unexpected WrapCode error: %s", coded)
+	}
+
+	if Wrap(nil, "message") != nil || WrapCode(nil, ErrInternal, "") != nil {
+		t.Error("wrapping nil must return nil")
+	}
+}
+
+func TestFields(t *testing.T) {
+	err := NotFound(fakeReason).
+		WithField("user_id", 42).
+		WithFields(map[string]any{"tenant": "acme", "active": true}).
+		WithField("tenant", "globex").
+		Err()
+
+	want := "code:NOT_FOUND,reason:" + fakeReason + ",id:" + ID(err) + ",fields:[active=true tenant=globex user_id=42]"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if v, ok := err.(*AError).Field("user_id"); !ok || v != 42 {
+		t.Errorf("Field(user_id) = %v, %v", v, ok)
+	}
+	if got := Fields(Wrap(err, "wrapped")); len(got) != 3 || got["tenant"] != "globex" {
+		t.Errorf("Fields() = %v", got)
+	}
+}
+
+func TestID(t *testing.T) {
+	SetIDGenerator(SequenceGenerator("ref-"))
+	defer SetIDGenerator(ULIDGenerator())
+
+	first := Internal(fakeReason).Err()
+	second := Internal(fakeReason).Err()
+	if ID(first) != "ref-1" || ID(second) != "ref-2" {
+		t.Errorf("unexpected IDs %q, %q", ID(first), ID(second))
+	}
+	if got := ID(Wrap(first, "wrapped")); got != "ref-1" {
+		t.Errorf("wrapped ID = %q, want ref-1", got)
+	}
+
+	SetIDGenerator(nil)
+	if got := ID(Internal(fakeReason).Err()); got != "" {
+		t.Errorf("ID = %q with generation turned off", got)
+	}
+
+	for _, g := range []IDGenerator{ULIDGenerator(), UUIDv7Generator()} {
+		a, b := string(g.AppendID(nil)), string(g.AppendID(nil))
+		if a == b {
+			t.Errorf("%T generated %q twice", g, a)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	err := Wrap(C(), "wrapped")
+	line := err.(*AError).line()
+
+	if got := fmt.Sprintf("%v", err); got != string(line) {
+		t.Errorf("%%v = %q, want %q", got, line)
+	}
+	if got := fmt.Sprintf("%s", err); got != string(line) {
+		t.Errorf("%%s = %q, want %q", got, line)
+	}
+	if got := fmt.Sprintf("%q", err); got != fmt.Sprintf("%q", line) {
+		t.Errorf("%%q = %s", got)
+	}
+
+	full := fmt.Sprintf("%+v", err)
+	for _, part := range []string{"\ncaused by: code:INTERNAL", "\ncaused by: fail", "aerrors.C\n"} {
+		if !strings.Contains(full, part) {
+			t.Errorf("%%+v = %q, missing %q", full, part)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	inner := NotFound("user not found").WithField("user_id", "42").WithParent(errExample).Err()
+	sent := Wrap(inner, "loading profile")
+
+	data, err := json.Marshal(sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got AError
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Error() != sent.Error() {
+		t.Errorf("Error() = %q, want %q", got.Error(), sent.Error())
+	}
+	if TypeCode(&got) != ErrNotFound.TypeCode() ||
+		HTTPCode(&got) != ErrNotFound.HTTPCode() ||
+		GRPCCode(&got) != ErrNotFound.GRPCCode() {
+		t.Errorf("unexpected codes after decoding: %s", &got)
+	}
+	if ID(&got) != ID(sent) || got.Stack() != sent.(*AError).Stack() {
+		t.Error("ID or stack was not decoded")
+	}
+
+	var parent *AError
+	if !errors.As(got.Unwrap(), &parent) || parent.Reason() != "user not found" {
+		t.Fatalf("parent was not decoded as an AError: %v", got.Unwrap())
+	}
+	if v, _ := parent.Field("user_id"); v != "42" {
+		t.Errorf("parent field user_id = %v", v)
+	}
+	if parent.Unwrap().Error() != errExample.Error() {
+		t.Errorf("opaque parent = %v", parent.Unwrap())
+	}
+
+	again, err := json.Marshal(&got)
+	if err != nil || string(again) != string(data) {
+		t.Errorf("round trip changed the encoding:\n%s\n%s", again, data)
+	}
+}
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil), &SlogOptions{Stack: true}))
+
+	parent := Internal(fakeReason).WithStack().Err()
+	err := NotFound(fakeReason).WithParent(parent).WithField("user_id", 42).WithStack().Err()
+	logger.Error("request failed", slog.Any("err", fmt.Errorf("handler: %w", err)))
+
+	var got struct {
+		Err struct {
+			Code   string
+			Reason string
+			HTTP   int
+			GRPC   string
+			ID     string
+			Parent string
+			Fields map[string]any
+		}
+		ErrStack string `json:"err_stack"`
+	}
+	if e := json.Unmarshal(buf.Bytes(), &got); e != nil {
+		t.Fatalf("%v: %s", e, buf.Bytes())
+	}
+	if got.Err.Code != "NOT_FOUND" || got.Err.Reason != fakeReason || got.Err.HTTP != 404 ||
+		got.Err.GRPC != "NotFound" || got.Err.ID != ID(err) || got.Err.Fields["user_id"] != 42.0 {
+		t.Errorf("unexpected log line %s", buf.Bytes())
+	}
+	if got.Err.Parent != string(parent.(*AError).line()) {
+		t.Errorf("parent = %q, want the line of the parent without its stack", got.Err.Parent)
+	}
+	if !strings.Contains(got.ErrStack, "TestSlog") {
+		t.Errorf("stack attribute missing in %s", buf.Bytes())
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	err := A().(*AError)
+
+	frames := err.StackTrace()
+	if len(frames) < 4 {
+		t.Fatalf("StackTrace() = %v", frames)
+	}
+	for i, name := range []string{"C", "B", "A", "TestStackTrace"} {
+		if want := "github.com/htquangg/aerrors." + name; frames[i].Function != want {
+			t.Errorf("frame %d = %s, want %s", i, frames[i].Function, want)
+		}
+		if !strings.HasSuffix(frames[i].File, "aerrors_test.go") || frames[i].Line == 0 {
+			t.Errorf("frame %d = %s:%d", i, frames[i].File, frames[i].Line)
+		}
+	}
+	if !strings.HasSuffix(err.Error(), err.Stack()) || !strings.Contains(err.Error(), "\n") {
+		t.Errorf("Error() does not end with the stack: %q", err.Error())
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	for function, want := range map[string]string{
+		"github.com/org/svc/pkg.(*T).Method":        "github.com/org/svc/pkg",
+		"gopkg.in/yaml%2ev3.(*decoder).unmarshal":   "gopkg.in/yaml.v3",
+		"gopkg.in/yaml.v3.(*decoder).unmarshal":     "gopkg.in/yaml.v3",
+		"gopkg.in/yaml.v3.Unmarshal":                "gopkg.in/yaml.v3",
+		"gopkg.in/yaml.v3.Unmarshal.func1":          "gopkg.in/yaml.v3",
+		"example.com/pkg.v2":                        "example.com/pkg",
+		"main.main":                                 "main",
+		"runtime.gopanic":                           "runtime",
+		"github.com/org/svc/pkg.Func.v2.func1":      "github.com/org/svc/pkg",
+		"github.com/org/svc%2ev1/pkg.Func.v2.func1": "github.com/org/svc.v1/pkg",
+	} {
+		if got := funcPackage(function); got != want {
+			t.Errorf("funcPackage(%q) = %q, want %q", function, got, want)
+		}
+	}
+
+	cfg := &stackConfig{trimPaths: true}
+	if got := cfg.trim(Frame{Function: "main.main", File: "/home/ci/svc/main.go"}); got != mainPackage()+"/main.go" {
+		t.Errorf("trim() = %q", got)
+	}
+}
+
+func TestStackOptions(t *testing.T) {
+	frames := A().(*AError).StackTrace()
+	if got := frames[0].File; got != "github.com/htquangg/aerrors/aerrors_test.go" {
+		t.Errorf("trimmed file = %s", got)
+	}
+	if got := frames[len(frames)-2].File; got != "testing/testing.go" {
+		t.Errorf("trimmed file = %s", got)
+	}
+
+	SetStackOptions(StackDepth(2), StackTrimPaths(false))
+	defer SetStackOptions()
+	frames = A().(*AError).StackTrace()
+	if len(frames) != 2 || !strings.HasPrefix(frames[0].File, "/") {
+		t.Errorf("StackTrace() = %v", frames)
+	}
+
+	err := Internal(fakeReason).WithStackOptions(
+		StackSkip(1),
+		StackDepth(32),
+		StackFilters(RuntimeFrames, TestingFrames),
+	).Err().(*AError)
+	frames = err.StackTrace()
+	if len(frames) != 0 {
+		t.Errorf("StackTrace() = %v", frames)
+	}
+}
+
+func dbErr(err error) error {
+	Helper()
+	return Internal("database failure").WithParent(err).WithStack().Err()
+}
+
+func wrapDBErr(err error) error {
+	Helper()
+	return dbErr(err)
+}
+
+func TestHelper(t *testing.T) {
+	frames := wrapDBErr(errExample).(*AError).StackTrace()
+	if got := frames[0].Function; got != "github.com/htquangg/aerrors.TestHelper" {
+		t.Errorf("top frame = %s, want the caller of the helpers", got)
+	}
+
+	RegisterHelpers("github.com/htquangg/aerrors.C")
+	defer helpers.Delete("github.com/htquangg/aerrors.C")
+	frames = A().(*AError).StackTrace()
+	if got := frames[0].Function; got != "github.com/htquangg/aerrors.B" {
+		t.Errorf("top frame = %s, want the caller of the registered helper", got)
+	}
+
+	SetStackPolicy(StackCallerOnly)
+	defer SetStackPolicy(nil)
+	err := wrapDBErr(errExample).(*AError)
+	if frames := err.StackTrace(); len(frames) != 1 || frames[0].Function != "github.com/htquangg/aerrors.TestHelper" {
+		t.Errorf("StackTrace() = %v, want the caller of the helpers", frames)
+	}
+}
+
+func TestFilteredStack(t *testing.T) {
+	err := Internal(fakeReason).WithStackOptions(StackFilters(func(Frame) bool { return true })).Err().(*AError)
+	if got := err.Error(); got != string(err.line()) || err.Stack() != "" {
+		t.Errorf("Error() = %q, want no stack", got)
+	}
+	if got := fmt.Sprintf("%+v", err); strings.HasSuffix(got, "\n") {
+		t.Errorf("%%+v = %q, want no stack", got)
+	}
+}
+
+func TestStackPolicy(t *testing.T) {
+	SetStackPolicy(StackByCode(map[Code]StackMode{ErrInternal: StackAlways}, StackNever))
+	defer SetStackPolicy(nil)
+
+	if err := NotFound(fakeReason).WithStack().Err().(*AError); len(err.StackTrace()) != 0 {
+		t.Error("NOT_FOUND captured a stack")
+	}
+	if err := Internal(fakeReason).WithStack().Err().(*AError); len(err.StackTrace()) < 2 {
+		t.Error("INTERNAL did not capture the whole stack")
+	}
+
+	ctx := ContextWithStackPolicy(context.Background(), StackCallerOnly)
+	err := NotFound(fakeReason).WithContext(ctx).WithStack().Err().(*AError)
+	if frames := err.StackTrace(); len(frames) != 1 || frames[0].Function != "github.com/htquangg/aerrors.TestStackPolicy" {
+		t.Errorf("StackTrace() = %v, want the caller only", frames)
+	}
+
+	SetStackPolicy(StackSampled(0, StackAlways))
+	if err := Internal(fakeReason).WithStack().Err().(*AError); len(err.StackTrace()) != 0 {
+		t.Error("a stack was captured with a sampling rate of 0")
+	}
+}
+
+func BenchmarkStackPolicy(b *testing.B) {
+	defer SetStackPolicy(nil)
+	for _, bc := range []struct {
+		name   string
+		policy StackPolicy
+	}{
+		{"Always", StackAlways},
+		{"Never", StackNever},
+		{"CallerOnly", StackCallerOnly},
+		{"ByCode", StackByCode(map[Code]StackMode{ErrInternal: StackAlways}, StackNever)},
+		{"Sampled10", StackSampled(0.1, StackAlways)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			SetStackPolicy(bc.policy)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					err := NotFound(fakeReason).
+						WithMessage(fakeMessage).
+						WithStack().
+						Err()
+					Release(err)
+				}
+			})
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	const errPaymentRequired Code = "PAYMENT_REQUIRED"
+	if !errPaymentRequired.Registered() {
+		MustRegister(errPaymentRequired, 402, codes.FailedPrecondition)
+	}
+
+	if errPaymentRequired.HTTPCode() != 402 || errPaymentRequired.GRPCCode() != codes.FailedPrecondition {
+		t.Errorf("unexpected codes for %s", errPaymentRequired)
+	}
+	if got := HTTPCode(New(errPaymentRequired, fakeReason).Err()); got != 402 {
+		t.Errorf("HTTPCode() = %d, want 402", got)
+	}
+	if !slices.Contains(Codes(), errPaymentRequired) || !slices.Contains(Codes(), ErrNotFound) {
+		t.Errorf("Codes() = %v", Codes())
+	}
+	if codeToError(codes.FailedPrecondition) != ErrFailedPrecondition {
+		t.Error("a later registration changed the canonical code")
+	}
+
+	for _, bc := range []struct {
+		code   Code
+		status int
+		opts   []CodeOption
+	}{
+		{errPaymentRequired, 402, nil},
+		{"", 402, nil},
+		{"QUOTA_EXCEEDED", 42, nil},
+		{"QUOTA_EXCEEDED", 429, []CodeOption{GRPCDefault()}},
+		{"QUOTA_EXCEEDED", 429, []CodeOption{InFamily(ErrBadRequest)}},
+		{"QUOTA_EXCEEDED", 429, []CodeOption{InFamily(ErrNotFound)}},
+	} {
+		if err := Register(bc.code, bc.status, codes.FailedPrecondition, bc.opts...); err == nil {
+			t.Errorf("Register(%q, %d) did not fail", bc.code, bc.status)
+		}
+	}
+}
+
+func TestFamilies(t *testing.T) {
+	if Canonical(ErrConflict) != ErrAlreadyExists || Canonical(ErrGatewayTimeout) != ErrDeadlineExceeded ||
+		Canonical(ErrNotFound) != ErrNotFound || Canonical("NOT_REGISTERED") != "NOT_REGISTERED" {
+		t.Error("unexpected canonical codes")
+	}
+	if !SameFamily(ErrUnauthorized, ErrUnauthenticated) || SameFamily(ErrAborted, ErrAlreadyExists) {
+		t.Error("unexpected families")
+	}
+	if got := Family(ErrRequestTimeout); !slices.Equal(got, []Code{ErrDeadlineExceeded, ErrRequestTimeout, ErrGatewayTimeout}) {
+		t.Errorf("Family() = %v", got)
+	}
+
+	const errQuotaExceeded Code = "QUOTA_EXCEEDED"
+	if !errQuotaExceeded.Registered() {
+		MustRegister(errQuotaExceeded, 429, codes.ResourceExhausted, InFamily(ErrResourceExhausted))
+	}
+	err := New(errQuotaExceeded, fakeReason).Err()
+	if !errors.Is(err, New(ErrTooManyRequests, "").Err()) ||
+		!IsAlreadyExists(New(ErrConflict, fakeReason).Err().(*AError)) {
+		t.Error("AError.Is does not match the family of the target code")
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	local := NotFound(fakeReason).Err()
+	data, _ := json.Marshal(local)
+	decoded := &AError{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, err := range map[string]error{
+		"local":    local,
+		"wrapped":  fmt.Errorf("handler: %w", local),
+		"received": ReceiveGRPCError(SendGRPCError(local)),
+		"decoded":  decoded,
+		"joined":   errors.Join(errExample, local),
+	} {
+		if !IsNotFound(err) || IsInternal(err) || IsUnknown(err) {
+			t.Errorf("%s: unexpected predicate results for %v", name, err)
+		}
+	}
+
+	if IsNotFound(nil) || !IsUnknown(errExample) || IsNotFound(errExample) {
+		t.Error("unexpected predicate results for errors without code")
+	}
+	if !IsInvalidArgument(New(ErrBadRequest, "").Err()) || !IsConflict(New(ErrAlreadyExists, "").Err()) {
+		t.Error("predicates do not match the family")
+	}
+	if IsAlreadyExists(New(ErrAborted, "").Err()) {
+		t.Error("IsAlreadyExists matches ABORTED")
+	}
+	if wrapped := WrapCode(local, ErrInternal, "lookup failed"); !IsInternal(wrapped) || IsNotFound(wrapped) || !errors.Is(wrapped, ErrNotFound) {
+		t.Error("predicates do not stop at the first code of the chain")
+	}
+}
+
+func TestNilAError(t *testing.T) {
+	var e *AError
+	var err error = e
+	if IsNotFound(err) || TypeCode(err) != ErrOK.TypeCode() || HTTPCode(err) != http.StatusOK ||
+		GRPCCode(err) != codes.OK || errors.Is(err, ErrNotFound) || ID(err) != "" {
+		t.Errorf("nil *AError does not report no error")
+	}
+	if IsNotFound(fmt.Errorf("wrapped: %w", err)) || e.Reason() != "" || len(e.Fields()) != 0 || e.Stack() != "" {
+		t.Errorf("nil *AError does not report no error")
+	}
+
+	var buf bytes.Buffer
+	slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil), &SlogOptions{Stack: true})).Error("failed", slog.Any("err", err))
+	if !strings.Contains(buf.String(), `"err":"<nil>"`) {
+		t.Errorf("unexpected log line %s", buf.Bytes())
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	for _, bc := range []struct {
+		builder Builder
+		code    Code
+	}{
+		{AlreadyExists(fakeReason), ErrAlreadyExists},
+		{DeadlineExceeded(fakeReason), ErrDeadlineExceeded},
+		{Conflict(fakeReason), ErrConflict},
+		{GatewayTimeout(fakeReason), ErrGatewayTimeout},
+		{Unauthentication(fakeReason), ErrUnauthenticated},
+		{NotFoundf("user %d not found", 42), ErrNotFound},
+	} {
+		if got := bc.builder.Err().(*AError).Code(); got != bc.code {
+			t.Errorf("constructor built %s, want %s", got, bc.code)
+		}
+	}
+	if got := NotFoundf("user %d not found", 42).Err().(*AError).Reason(); got != "user 42 not found" {
+		t.Errorf("Reason() = %q", got)
+	}
+}
+
+func TestIsCode(t *testing.T) {
+	errUserNotFound := Sentinel(ErrNotFound, "user not found")
+	local := NotFound("user not found").Err()
+	data, _ := json.Marshal(local)
+	decoded := &AError{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, err := range map[string]error{
+		"local":    local,
+		"wrapped":  Wrap(local, "loading profile"),
+		"received": ReceiveGRPCError(SendGRPCError(local)),
+		"decoded":  decoded,
+	} {
+		if !errors.Is(err, ErrNotFound) || !errors.Is(err, errUserNotFound) {
+			t.Errorf("%s: errors.Is does not match the code or the sentinel", name)
+		}
+		if errors.Is(err, ErrInternal) || errors.Is(err, Sentinel(ErrNotFound, "order not found")) {
+			t.Errorf("%s: errors.Is matches another code or reason", name)
+		}
+	}
+
+	if !errors.Is(New(ErrGatewayTimeout, "").Err(), ErrDeadlineExceeded) {
+		t.Error("errors.Is does not match a family alias")
+	}
+	if received := ReceiveGRPCError(SendGRPCError(New(ErrBadGateway, "").Err())); errors.Is(received, ErrAborted) {
+		t.Error("errors.Is matches a code sharing the GRPC code only")
+	}
+}
+
+var (
+	errOrderNotFound = Define(ErrNotFound, "order_not_found", "order %d not found")
+	errOrderLocked   = Define(ErrFailedPrecondition, "order_locked", "order is locked")
+)
+
+func TestDefine(t *testing.T) {
+	err := errOrderNotFound.New(42).WithField("tenant", "acme").Err().(*AError)
+	if got := err.Message(); got != "order 42 not found" {
+		t.Errorf("Message() = %q", got)
+	}
+	if err.Code() != ErrNotFound || err.Reason() != "order_not_found" {
+		t.Errorf("got %s/%s", err.Code(), err.Reason())
+	}
+	if got := errOrderLocked.New().Err().(*AError).Message(); got != "order is locked" {
+		t.Errorf("Message() = %q", got)
+	}
+
+	wrapped := errOrderLocked.Wrap(io.EOF).Err()
+	if !errors.Is(wrapped, io.EOF) || !errors.Is(wrapped, errOrderLocked) {
+		t.Error("errors.Is does not match the parent or the definition")
+	}
+	if !errors.Is(Wrap(err, "checkout"), errOrderNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Error("errors.Is does not match the definition through a wrapper or the code")
+	}
+	if errors.Is(err, errOrderLocked) {
+		t.Error("errors.Is matches another definition")
+	}
+	if !errors.Is(ReceiveGRPCError(SendGRPCError(err)), errOrderNotFound) {
+		t.Error("errors.Is does not match a received error on code and reason")
+	}
+
+	defs := Definitions()
+	if len(defs) < 2 || defs[0] != errOrderNotFound || defs[1] != errOrderLocked {
+		t.Errorf("Definitions() = %v", defs)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Define does not panic on a duplicate")
+		}
+	}()
+	Define(ErrNotFound, "order_not_found", "")
+}
+
+type quotaError struct{}
+
+func (quotaError) Error() string { return "quota exceeded" }
+
+func TestFrom(t *testing.T) {
+	_, dialErr := net.Dial("tcp", "127.0.0.1:0")
+	_, openErr := os.Open("/does/not/exist")
+
+	for _, tc := range []struct {
+		err  error
+		code Code
+	}{
+		{context.Canceled, ErrCanceled},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), ErrDeadlineExceeded},
+		{openErr, ErrNotFound},
+		{os.ErrPermission, ErrPermissionDenied},
+		{sql.ErrNoRows, ErrNotFound},
+		{os.ErrDeadlineExceeded, ErrDeadlineExceeded},
+		{dialErr, ErrUnavailable},
+		{errExample, ErrUnknown},
+	} {
+		e := From(tc.err)
+		if e.Code() != tc.code || !errors.Is(e, tc.err) {
+			t.Errorf("From(%v) = %s", tc.err, e)
+		}
+		if TypeCode(tc.err) != tc.code.TypeCode() || HTTPCode(tc.err) != tc.code.HTTPCode() ||
+			GRPCCode(tc.err) != tc.code.GRPCCode() || !IsCode(tc.err, tc.code) {
+			t.Errorf("%v is not reported as %s", tc.err, tc.code)
+		}
+		if got := ReceiveGRPCError(SendGRPCError(tc.err)); TypeCode(got) != tc.code.TypeCode() {
+			t.Errorf("%v is sent as %s", tc.err, TypeCode(got))
+		}
+	}
+
+	ae := NotFound(fakeReason).WithParent(context.Canceled).Err()
+	if From(ae) != ae || From(fmt.Errorf("loading: %w", ae)) != ae || From(nil) != nil {
+		t.Error("From does not return the AError of the chain")
+	}
+	if From(fmt.Errorf("lookup: %w", ErrAborted)).Code() != ErrAborted {
+		t.Error("From does not keep the code of the chain")
+	}
+
+	RegisterClassifier(ClassifyAs[quotaError](ErrResourceExhausted, "quota"))
+	if e := From(fmt.Errorf("send: %w", quotaError{})); e.Code() != ErrResourceExhausted || e.Reason() != "quota" {
+		t.Errorf("From does not use a registered classifier: %s", e)
+	}
+}
@@ -0,0 +1,129 @@
+package aerrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// MessageCatalog is a Catalog filled from maps, JSON or TOML files.
+type MessageCatalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}
+
+// NewMessageCatalog returns an empty catalog.
+func NewMessageCatalog() *MessageCatalog {
+	return &MessageCatalog{messages: map[string]map[string]string{}}
+}
+
+// Lookup returns the message template of key in locale.
+func (c *MessageCatalog) Lookup(locale, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	msg, ok := c.messages[CanonicalLocale(locale)][key]
+	return msg, ok
+}
+
+// Locales returns the locales of the catalog.
+func (c *MessageCatalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return sortedKeys(c.messages)
+}
+
+// Add adds messages to locale, replacing the ones with the same keys.
+func (c *MessageCatalog) Add(locale string, messages map[string]string) {
+	locale = CanonicalLocale(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.messages[locale]
+	if m == nil {
+		m = make(map[string]string, len(messages))
+		c.messages[locale] = m
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+}
+
+// AddJSON adds the messages of a JSON object to locale. Nested objects give keys joined
+// with dots, {"user": {"not_found": "..."}} holding the "user.not_found" key.
+func (c *MessageCatalog) AddJSON(locale string, data []byte) error {
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("aerrors: decode %s catalog: %w", locale, err)
+	}
+	messages := map[string]string{}
+	if err := flattenMessages(messages, "", obj); err != nil {
+		return fmt.Errorf("aerrors: decode %s catalog: %w", locale, err)
+	}
+	c.Add(locale, messages)
+	return nil
+}
+
+func flattenMessages(dst map[string]string, prefix string, obj map[string]any) error {
+	for k, v := range obj {
+		switch v := v.(type) {
+		case string:
+			dst[prefix+k] = v
+		case map[string]any:
+			if err := flattenMessages(dst, prefix+k+".", v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %q is not a string", prefix+k)
+		}
+	}
+	return nil
+}
+
+// AddTOML adds the messages of a TOML document to locale. Tables give keys joined with
+// dots the same way as nested JSON objects.
+func (c *MessageCatalog) AddTOML(locale string, data []byte) error {
+	var obj map[string]any
+	if err := toml.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("aerrors: decode %s catalog: %w", locale, err)
+	}
+	messages := map[string]string{}
+	if err := flattenMessages(messages, "", obj); err != nil {
+		return fmt.Errorf("aerrors: decode %s catalog: %w", locale, err)
+	}
+	c.Add(locale, messages)
+	return nil
+}
+
+// AddFS adds the catalog files found in dir of fsys, such as an embed.FS. Files are named
+// after their locale and format, such as "vi.json" or "en-US.toml"; other files are skipped.
+func (c *MessageCatalog) AddFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("aerrors: read catalogs: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := path.Ext(name)
+		if entry.IsDir() || (ext != ".json" && ext != ".toml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("aerrors: read catalogs: %w", err)
+		}
+		locale := strings.TrimSuffix(name, ext)
+		if ext == ".json" {
+			err = c.AddJSON(locale, data)
+		} else {
+			err = c.AddTOML(locale, data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
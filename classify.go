@@ -0,0 +1,132 @@
+package aerrors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// Classifier maps errors that carry no code onto a code and a reason, reporting false for
+// the errors it does not know.
+type Classifier func(err error) (code Code, reason string, ok bool)
+
+// ClassifyIs returns a Classifier mapping the errors matching target, with errors.Is, onto
+// code. An empty reason is replaced by the message of target.
+func ClassifyIs(target error, code Code, reason string) Classifier {
+	if reason == "" {
+		reason = target.Error()
+	}
+	return func(err error) (Code, string, bool) {
+		if errors.Is(err, target) {
+			return code, reason, true
+		}
+		return "", "", false
+	}
+}
+
+// ClassifyAs returns a Classifier mapping the errors having an error of type T in their
+// chain, with errors.As, onto code.
+func ClassifyAs[T error](code Code, reason string) Classifier {
+	return func(err error) (Code, string, bool) {
+		var t T
+		if errors.As(err, &t) {
+			return code, reason, true
+		}
+		return "", "", false
+	}
+}
+
+// classifierSet is never modified once published; RegisterClassifier publishes a copy.
+type classifierSet struct {
+	custom  []Classifier
+	builtin []Classifier
+}
+
+var (
+	classifiersMu sync.Mutex
+	classifiers   atomic.Pointer[classifierSet]
+)
+
+func init() {
+	classifiers.Store(&classifierSet{builtin: []Classifier{
+		ClassifyIs(context.Canceled, ErrCanceled, ""),
+		ClassifyIs(context.DeadlineExceeded, ErrDeadlineExceeded, ""),
+		ClassifyIs(fs.ErrNotExist, ErrNotFound, ""),
+		ClassifyIs(fs.ErrExist, ErrAlreadyExists, ""),
+		ClassifyIs(fs.ErrPermission, ErrPermissionDenied, ""),
+		ClassifyIs(sql.ErrNoRows, ErrNotFound, ""),
+		classifyNet,
+	}})
+}
+
+// classifyNet maps network timeouts onto DEADLINE_EXCEEDED and the other failures of
+// network operations onto UNAVAILABLE.
+func classifyNet(err error) (Code, string, bool) {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrDeadlineExceeded, "network timeout", true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrUnavailable, "network unavailable", true
+	}
+	return "", "", false
+}
+
+// RegisterClassifier adds classifiers tried by From, in the order they are registered,
+// before the built-in ones mapping the errors of context, io/fs, database/sql and net.
+func RegisterClassifier(cs ...Classifier) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+
+	cur := classifiers.Load()
+	classifiers.Store(&classifierSet{
+		custom:  append(cur.custom[:len(cur.custom):len(cur.custom)], cs...),
+		builtin: cur.builtin,
+	})
+}
+
+// Classify returns the code and the reason the classifiers map err onto, or ErrUnknown
+// and false when none of them knows err.
+func Classify(err error) (Code, string, bool) {
+	set := classifiers.Load()
+	for _, cs := range [][]Classifier{set.custom, set.builtin} {
+		for _, c := range cs {
+			if code, reason, ok := c(err); ok {
+				return code, reason, true
+			}
+		}
+	}
+	return ErrUnknown, "", false
+}
+
+// From returns err as an *AError.
+//
+// When the chain of err holds an *AError it is returned. Otherwise the result has err as its
+// parent and the code of the chain, if it holds a Code, or the one of the classifiers,
+// ErrUnknown when none of them knows err.
+//
+// If err is nil then From returns nil.
+func From(err error) *AError {
+	if err == nil {
+		return nil
+	}
+	var ae *AError
+	if errors.As(err, &ae) {
+		return ae
+	}
+	var (
+		code   Code
+		reason string
+	)
+	if !errors.As(err, &code) {
+		code, reason, _ = Classify(err)
+	}
+	e := newAError(code, reason)
+	e.addParent(err)
+	return e.Err().(*AError)
+}
@@ -0,0 +1,112 @@
+package aerrors
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Definition is an immutable error template returned by Define.
+type Definition struct {
+	code    Code
+	reason  string
+	message string
+}
+
+var (
+	definitionsMu sync.Mutex
+	definitions   []*Definition
+	definitionSet = map[Code]map[string]bool{}
+)
+
+// Define returns the definition of the errors with the given code and reason, whose
+// message is built from messageTemplate, a fmt format:
+//
+//	var ErrUserNotFound = aerrors.Define(aerrors.ErrNotFound, "user_not_found", "user %d not found")
+//
+//	return ErrUserNotFound.New(userID).Err()
+//
+// errors.Is matches a Definition with every error created from it, and with the errors of
+// its code and reason not created from a definition, such as received ones. Defining the
+// same code and reason twice panics, definitions are meant to be package level variables.
+func Define(code Code, reason, messageTemplate string) *Definition {
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+
+	if definitionSet[code][reason] {
+		panic(fmt.Sprintf("aerrors: %s error %q is already defined", code, reason))
+	}
+	if definitionSet[code] == nil {
+		definitionSet[code] = map[string]bool{}
+	}
+	definitionSet[code][reason] = true
+
+	d := &Definition{code: code, reason: reason, message: messageTemplate}
+	definitions = append(definitions, d)
+	return d
+}
+
+// Definitions returns every definition in the order they were defined, such as to
+// generate documentation.
+func Definitions() []*Definition {
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+	return append([]*Definition(nil), definitions...)
+}
+
+// New returns a Builder for an error of the definition, with the message template
+// formatted with args.
+func (d *Definition) New(args ...any) Builder {
+	e := newAError(d.code, d.reason)
+	e.def = d
+	e.message = d.format(args)
+	return e
+}
+
+// Wrap is New with err added as the parent of the error.
+func (d *Definition) Wrap(err error, args ...any) Builder {
+	e := newAError(d.code, d.reason)
+	e.def = d
+	e.message = d.format(args)
+	e.addParent(err)
+	return e
+}
+
+func (d *Definition) format(args []any) string {
+	if len(args) == 0 {
+		return d.message
+	}
+	return fmt.Sprintf(d.message, args...)
+}
+
+// Code returns the code of the definition.
+func (d *Definition) Code() Code {
+	return d.code
+}
+
+// Reason returns the reason of the definition.
+func (d *Definition) Reason() string {
+	return d.reason
+}
+
+// MessageTemplate returns the message template of the definition.
+func (d *Definition) MessageTemplate() string {
+	return d.message
+}
+
+func (d *Definition) Error() string {
+	return "code:" + d.code.Error() + ",reason:" + d.reason
+}
+
+func (d *Definition) TypeCode() string {
+	return d.code.TypeCode()
+}
+
+func (d *Definition) HTTPCode() int {
+	return d.code.HTTPCode()
+}
+
+func (d *Definition) GRPCCode() codes.Code {
+	return d.code.GRPCCode()
+}
@@ -4,18 +4,24 @@
 
 // Package aerrors builds on Go 1.13 errors adding HTTP and GRPC code to your errors.
 //
-// Wrapping any error other than an Error will return an error with the message formatted
-// as "<message>: <error>".
+// Wrapping any error other than an Error with Wrap or Wrapf will return an error with the
+// message formatted as "<message>: <error>".
 //
-// Wrapping an Error will return an error with an unaltered error message.
+// Wrapping an Error will return an error with an unaltered error message, keeping the code,
+// reason and stack of the wrapped Error. WrapCode replaces the code and reason instead.
 //
 // # Transmitting errors over GRPC
 //
 // The aerrors produced with wrap, that have also been wrapped first with an Err* can be
 // send with SendGRPCError() and received with ReceiveGRPCError().
 //
-// You may want to create and use GRPC server and client interceptors to avoid having to
-// call the Send/Receive methods in every handler.
+// UnaryServerInterceptor and StreamServerInterceptor send the errors of handlers the way
+// SendGRPCErrorContext does and recover their panics, so that handlers do not have to call
+// SendGRPCError. UnaryClientInterceptor and StreamClientInterceptor do the same for
+// ReceiveGRPCError on the client side.
+//
+// What is sent of an error depends on the exposure policy, see SetExposurePolicy: by
+// default untrusted callers only get its code, reason, message and ID.
 //
 // The Err* constants are errors and can be used directly is desired.
 package aerrors
@@ -25,12 +25,14 @@ type ErrorDetail struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ID       string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Reason   string `protobuf:"bytes,2,opt,name=Reason,proto3" json:"Reason,omitempty"`
-	Message  string `protobuf:"bytes,3,opt,name=Message,proto3" json:"Message,omitempty"`
-	TypeCode string `protobuf:"bytes,4,opt,name=TypeCode,proto3" json:"TypeCode,omitempty"`
-	HTTPCode int64  `protobuf:"varint,5,opt,name=HTTPCode,proto3" json:"HTTPCode,omitempty"`
-	GRPCCode int64  `protobuf:"varint,6,opt,name=GRPCCode,proto3" json:"GRPCCode,omitempty"`
+	ID       string            `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Reason   string            `protobuf:"bytes,2,opt,name=Reason,proto3" json:"Reason,omitempty"`
+	Message  string            `protobuf:"bytes,3,opt,name=Message,proto3" json:"Message,omitempty"`
+	TypeCode string            `protobuf:"bytes,4,opt,name=TypeCode,proto3" json:"TypeCode,omitempty"`
+	HTTPCode int64             `protobuf:"varint,5,opt,name=HTTPCode,proto3" json:"HTTPCode,omitempty"`
+	GRPCCode int64             `protobuf:"varint,6,opt,name=GRPCCode,proto3" json:"GRPCCode,omitempty"`
+	Fields   map[string]string `protobuf:"bytes,7,rep,name=Fields,proto3" json:"Fields,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Parents  []*ErrorDetail    `protobuf:"bytes,8,rep,name=Parents,proto3" json:"Parents,omitempty"`
 }
 
 func (x *ErrorDetail) Reset() {
@@ -107,11 +109,25 @@ func (x *ErrorDetail) GetGRPCCode() int64 {
 	return 0
 }
 
+func (x *ErrorDetail) GetFields() map[string]string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetParents() []*ErrorDetail {
+	if x != nil {
+		return x.Parents
+	}
+	return nil
+}
+
 var File_errorspb_proto protoreflect.FileDescriptor
 
 var file_errorspb_proto_rawDesc = []byte{
 	0x0a, 0x0e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x70, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x07, 0x61, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0xa3, 0x01, 0x0a, 0x0b, 0x45, 0x72,
+	0x12, 0x07, 0x61, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0xc8, 0x02, 0x0a, 0x0b, 0x45, 0x72,
 	0x72, 0x6f, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x61,
 	0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f,
@@ -121,16 +137,26 @@ var file_errorspb_proto_rawDesc = []byte{
 	0x79, 0x70, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x48, 0x54, 0x54, 0x50, 0x43,
 	0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x48, 0x54, 0x54, 0x50, 0x43,
 	0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x47, 0x52, 0x50, 0x43, 0x43, 0x6f, 0x64, 0x65, 0x18,
-	0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x47, 0x52, 0x50, 0x43, 0x43, 0x6f, 0x64, 0x65, 0x42,
-	0x7c, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x2e, 0x61, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x42, 0x0d,
-	0x45, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x70, 0x62, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a,
-	0x22, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x74, 0x71, 0x75,
-	0x61, 0x6e, 0x67, 0x2f, 0x61, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x3b, 0x61, 0x65, 0x72, 0x72,
-	0x6f, 0x72, 0x73, 0xa2, 0x02, 0x03, 0x41, 0x58, 0x58, 0xaa, 0x02, 0x07, 0x41, 0x65, 0x72, 0x72,
-	0x6f, 0x72, 0x73, 0xca, 0x02, 0x07, 0x41, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0xe2, 0x02, 0x13,
-	0x41, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64,
-	0x61, 0x74, 0x61, 0xea, 0x02, 0x07, 0x41, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x47, 0x52, 0x50, 0x43, 0x43, 0x6f, 0x64, 0x65, 0x12,
+	0x38, 0x0a, 0x06, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x20, 0x2e, 0x61, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x44,
+	0x65, 0x74, 0x61, 0x69, 0x6c, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x06, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x12, 0x2e, 0x0a, 0x07, 0x50, 0x61, 0x72,
+	0x65, 0x6e, 0x74, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x73, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c,
+	0x52, 0x07, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x46, 0x69, 0x65,
+	0x6c, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x42, 0x7c, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x2e, 0x61, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x73, 0x42, 0x0d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x70, 0x62, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x50, 0x01, 0x5a, 0x22, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x68, 0x74, 0x71, 0x75, 0x61, 0x6e, 0x67, 0x2f, 0x61, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73,
+	0x3b, 0x61, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0xa2, 0x02, 0x03, 0x41, 0x58, 0x58, 0xaa, 0x02,
+	0x07, 0x41, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0xca, 0x02, 0x07, 0x41, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x73, 0xe2, 0x02, 0x13, 0x41, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x5c, 0x47, 0x50, 0x42,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x07, 0x41, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -145,16 +171,19 @@ func file_errorspb_proto_rawDescGZIP() []byte {
 	return file_errorspb_proto_rawDescData
 }
 
-var file_errorspb_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_errorspb_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_errorspb_proto_goTypes = []any{
 	(*ErrorDetail)(nil), // 0: aerrors.ErrorDetail
+	nil,                 // 1: aerrors.ErrorDetail.FieldsEntry
 }
 var file_errorspb_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1, // 0: aerrors.ErrorDetail.Fields:type_name -> aerrors.ErrorDetail.FieldsEntry
+	0, // 1: aerrors.ErrorDetail.Parents:type_name -> aerrors.ErrorDetail
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_errorspb_proto_init() }
@@ -182,7 +211,7 @@ func file_errorspb_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_errorspb_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
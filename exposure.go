@@ -0,0 +1,147 @@
+package aerrors
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Detail selects the parts of errors sent to clients, on top of their code and reason.
+type Detail uint8
+
+const (
+	// DetailID sends the ID of the error.
+	DetailID Detail = 1 << iota
+	// DetailMessage sends the message of the error.
+	DetailMessage
+	// DetailFields sends the fields of the error.
+	DetailFields
+	// DetailLocalized sends the localized message of the error.
+	DetailLocalized
+	// DetailParents sends the parents of the error, their messages included.
+	DetailParents
+	// DetailRich sends the google.rpc details added with the builder, such as BadRequest.
+	DetailRich
+	// DetailDebug sends the stack of the error, as a google.rpc.DebugInfo over GRPC.
+	DetailDebug
+	// DetailText sends the text of the error, its parents and stack included, as the
+	// message of GRPC statuses instead of the message of the error.
+	DetailText
+
+	// DetailPublic sends the details meant for the users of the service.
+	DetailPublic = DetailID | DetailMessage | DetailLocalized | DetailRich
+	// DetailAll sends every detail.
+	DetailAll = DetailPublic | DetailFields | DetailParents | DetailDebug | DetailText
+)
+
+// Detail implements ExposurePolicy, so that a Detail can be used as a policy exposing the
+// same details of every error to every caller.
+func (d Detail) Exposure(Code, bool) Detail {
+	return d
+}
+
+// ExposurePolicy decides which details of an error with the given code are sent to a
+// caller, trusted or not.
+//
+// The code and the reason of errors are always sent.
+type ExposurePolicy interface {
+	Exposure(code Code, trusted bool) Detail
+}
+
+// ExposurePolicyFunc is an adapter to use an ordinary function as an ExposurePolicy.
+type ExposurePolicyFunc func(code Code, trusted bool) Detail
+
+// Exposure calls f(code, trusted).
+func (f ExposurePolicyFunc) Exposure(code Code, trusted bool) Detail {
+	return f(code, trusted)
+}
+
+// ExposeTrusted returns a policy deferring to trusted for trusted callers and to untrusted
+// for the others.
+func ExposeTrusted(trusted, untrusted ExposurePolicy) ExposurePolicy {
+	return ExposurePolicyFunc(func(code Code, isTrusted bool) Detail {
+		if isTrusted {
+			return trusted.Exposure(code, isTrusted)
+		}
+		return untrusted.Exposure(code, isTrusted)
+	})
+}
+
+// ExposeByCode returns a policy exposing the details given for the code of the error, or
+// else for the first member of its family having some, the canonical code first and then
+// the aliases in the order they were registered. Families missing from exposures defer to
+// otherwise.
+//
+//	aerrors.ExposeTrusted(aerrors.DetailAll, aerrors.ExposeByCode(map[aerrors.Code]aerrors.Detail{
+//		aerrors.ErrInternal: aerrors.DetailID,
+//	}, aerrors.DetailPublic))
+func ExposeByCode(exposures map[Code]Detail, otherwise ExposurePolicy) ExposurePolicy {
+	m := make(map[Code]Detail, len(exposures))
+	for code, d := range exposures {
+		m[code] = d
+	}
+	return ExposurePolicyFunc(func(code Code, trusted bool) Detail {
+		if d, ok := m[code]; ok {
+			return d
+		}
+		for _, member := range Family(code) {
+			if d, ok := m[member]; ok {
+				return d
+			}
+		}
+		return otherwise.Exposure(code, trusted)
+	})
+}
+
+// DefaultExposure sends every detail to trusted callers and the public ones to the others.
+var DefaultExposure = ExposeTrusted(DetailAll, DetailPublic)
+
+type exposurePolicyHolder struct {
+	p ExposurePolicy
+}
+
+var (
+	globalExposurePolicy atomic.Pointer[exposurePolicyHolder]
+	debugExposure        atomic.Bool
+)
+
+func init() {
+	SetExposurePolicy(DefaultExposure)
+}
+
+// SetExposurePolicy sets the policy deciding which details of errors are sent by
+// SendGRPCError, the server interceptors and WriteHTTPError. DefaultExposure is used by
+// default, and when policy is nil.
+func SetExposurePolicy(policy ExposurePolicy) {
+	if policy == nil {
+		policy = DefaultExposure
+	}
+	globalExposurePolicy.Store(&exposurePolicyHolder{p: policy})
+}
+
+// SetDebugExposure turns the debug mode on or off, sending every detail of errors to every
+// caller whatever the exposure policy. Never turn it on in production.
+func SetDebugExposure(enabled bool) {
+	debugExposure.Store(enabled)
+}
+
+type trustedCallerKey struct{}
+
+// ContextWithTrustedCaller returns a copy of ctx marking its caller as trusted, such as an
+// internal service authenticated with mTLS.
+func ContextWithTrustedCaller(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedCallerKey{}, true)
+}
+
+// IsTrustedCaller reports whether ctx was marked with ContextWithTrustedCaller.
+func IsTrustedCaller(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedCallerKey{}).(bool)
+	return trusted
+}
+
+// exposure returns the details of err sent to a caller, trusted or not.
+func exposure(err error, trusted bool) Detail {
+	if debugExposure.Load() {
+		return DetailAll
+	}
+	return globalExposurePolicy.Load().p.Exposure(Code(TypeCode(err)), trusted)
+}
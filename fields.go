@@ -0,0 +1,139 @@
+package aerrors
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Fielder is implemented by errors carrying structured key/value fields.
+type Fielder interface {
+	Fields() map[string]any
+}
+
+type field struct {
+	key   string
+	value any
+}
+
+// WithField attaches a key/value pair to the error, replacing any value already set for key.
+func (err *AError) WithField(key string, value any) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	err.setField(key, value)
+	return err
+}
+
+// WithFields attaches every key/value pair of fields to the error.
+func (err *AError) WithFields(fields map[string]any) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	for key, value := range fields {
+		err.setField(key, value)
+	}
+	return err
+}
+
+// Fields returns a copy of the fields attached to the error, or nil when there are none.
+func (err *AError) Fields() map[string]any {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	if len(err.fields) == 0 {
+		return nil
+	}
+	fields := make(map[string]any, len(err.fields))
+	for _, f := range err.fields {
+		fields[f.key] = f.value
+	}
+	return fields
+}
+
+// Field returns the value attached to the error for key.
+func (err *AError) Field(key string) (any, bool) {
+	if err == nil {
+		return nil, false
+	}
+	err.assertLive()
+	if i, ok := err.fieldIndex(key); ok {
+		return err.fields[i].value, true
+	}
+	return nil, false
+}
+
+// Fields returns the fields attached to the given error or nil when it has none
+func Fields(err error) map[string]any {
+	var e Fielder
+	if errors.As(err, &e) {
+		return e.Fields()
+	}
+	return nil
+}
+
+// setField keeps the fields sorted by key so that they render deterministically
+func (err *AError) setField(key string, value any) {
+	i, ok := err.fieldIndex(key)
+	if ok {
+		err.fields[i].value = value
+		return
+	}
+	err.fields = slices.Insert(err.fields, i, field{key: key, value: value})
+}
+
+func (err *AError) fieldIndex(key string) (int, bool) {
+	return slices.BinarySearchFunc(err.fields, key, func(f field, key string) int {
+		switch {
+		case f.key < key:
+			return -1
+		case f.key > key:
+			return 1
+		default:
+			return 0
+		}
+	})
+}
+
+func (err *AError) appendFields(dst []byte) []byte {
+	dst = append(dst, '[')
+	for i, f := range err.fields {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		dst = append(err.appendString(dst, f.key), '=')
+		dst = appendFieldValue(dst, f.value)
+	}
+	return append(dst, ']')
+}
+
+func appendFieldValue(dst []byte, value any) []byte {
+	switch v := value.(type) {
+	case string:
+		return append(dst, v...)
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(dst, v, 10)
+	case uint64:
+		return strconv.AppendUint(dst, v, 10)
+	case bool:
+		return strconv.AppendBool(dst, v)
+	case fmt.Stringer:
+		return append(dst, v.String()...)
+	default:
+		return fmt.Append(dst, v)
+	}
+}
+
+// fieldString formats a field value the same way it is rendered by Error().
+func fieldString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return string(appendFieldValue(nil, value))
+}
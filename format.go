@@ -0,0 +1,47 @@
+package aerrors
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Format implements fmt.Formatter.
+//
+//	%s, %v  the error on a single line, without the stack
+//	%+v     the error, each of its causes on its own line and the stack
+//	%q      the single line form, quoted
+func (err *AError) Format(s fmt.State, verb rune) {
+	err.assertLive()
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = s.Write(err.line())
+			err.formatCauses(s)
+			if frames := err.StackTrace(); len(frames) != 0 {
+				_, _ = s.Write(appendFrames([]byte{'\n'}, frames))
+			}
+			return
+		}
+		_, _ = s.Write(err.line())
+	case 's':
+		_, _ = s.Write(err.line())
+	case 'q':
+		_, _ = s.Write(strconv.AppendQuote(nil, BytesToString(err.line())))
+	default:
+		fmt.Fprintf(s, "%%!%c(*aerrors.AError=%s)", verb, err.line())
+	}
+}
+
+// formatCauses writes a "caused by" line for every cause, depth first.
+func (err *AError) formatCauses(w io.Writer) {
+	for _, parent := range err.parents {
+		_, _ = io.WriteString(w, "\ncaused by: ")
+		if p, ok := parent.(*AError); ok {
+			_, _ = w.Write(p.line())
+			p.formatCauses(w)
+			continue
+		}
+		fmt.Fprintf(w, "%+v", parent)
+	}
+}
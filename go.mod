@@ -3,6 +3,8 @@ module github.com/htquangg/aerrors
 go 1.22.2
 
 require (
+	github.com/BurntSushi/toml v1.6.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240722135656-d784300faade
 	google.golang.org/grpc v1.65.0
 	google.golang.org/protobuf v1.34.2
 )
@@ -10,5 +12,5 @@ require (
 require (
 	golang.org/x/net v0.27.0 // indirect
 	golang.org/x/sys v0.22.0 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20240722135656-d784300faade // indirect
+	golang.org/x/text v0.16.0 // indirect
 )
@@ -1,149 +1,47 @@
 package aerrors
 
 import (
+	"context"
 	"errors"
-	"fmt"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
 )
 
 type GRPCCoder interface {
 	GRPCCode() codes.Code
 }
 
-// nolint:gocyclo
-func (err Code) GRPCCode() codes.Code {
-	switch err {
-	// GRPC Errors
-	case ErrOK:
+// GRPCCode returns the GRPC code of the error's Code.
+func (err *AError) GRPCCode() codes.Code {
+	if err == nil {
 		return codes.OK
-	case ErrCanceled:
-		return codes.Canceled
-	case ErrUnknown:
-		return codes.Unknown
-	case ErrInvalidArgument:
-		return codes.InvalidArgument
-	case ErrDeadlineExceeded:
-		return codes.DeadlineExceeded
-	case ErrNotFound:
-		return codes.NotFound
-	case ErrAlreadyExists:
-		return codes.AlreadyExists
-	case ErrPermissionDenied:
-		return codes.PermissionDenied
-	case ErrResourceExhausted:
-		return codes.ResourceExhausted
-	case ErrFailedPrecondition:
-		return codes.FailedPrecondition
-	case ErrAborted:
-		return codes.Aborted
-	case ErrOutOfRange:
-		return codes.OutOfRange
-	case ErrUnimplemented:
-		return codes.Unimplemented
-	case ErrInternal:
-		return codes.Internal
-	case ErrUnavailable:
-		return codes.Unavailable
-	case ErrDataLoss:
-		return codes.DataLoss
-	case ErrUnauthenticated:
-		return codes.Unauthenticated
-
-	// HTTP Errors
-	case ErrBadRequest:
-		return codes.InvalidArgument
-	case ErrUnauthorized:
-		return codes.Unauthenticated
-	case ErrForbidden:
-		return codes.PermissionDenied
-	case ErrMethodNotAllowed:
-		return codes.Unimplemented
-	case ErrRequestTimeout:
-		return codes.DeadlineExceeded
-	case ErrConflict:
-		return codes.AlreadyExists
-	case ErrImATeapot:
-		return codes.Unknown
-	case ErrUnprocessableEntity:
-		return codes.InvalidArgument
-	case ErrTooManyRequests:
-		return codes.ResourceExhausted
-	case ErrUnavailableForLegalReasons:
-		return codes.Unavailable
-	case ErrInternalServerError:
-		return codes.Internal
-	case ErrNotImplemented:
-		return codes.Unimplemented
-	case ErrBadGateway:
-		return codes.Aborted
-	case ErrServiceUnavailable:
-		return codes.Unavailable
-	case ErrGatewayTimeout:
-		return codes.DeadlineExceeded
-	default:
-		return codes.Internal
 	}
+	err.assertLive()
+	if err.httpCode != 0 {
+		return err.grpcCode
+	}
+	return err.code.GRPCCode()
 }
 
+// GRPCStatus returns the status sent for the code to an untrusted caller.
 func (err Code) GRPCStatus() *status.Status {
-	return errToStatus(err)
+	return errToStatus(err, statusConfig{details: exposure(err, false)})
 }
 
+// GRPCStatus returns the status sent for the error to an untrusted caller, following the
+// exposure policy.
 func (err *AError) GRPCStatus() *status.Status {
-	return errToStatus(err)
-}
-
-type grpcError struct {
-	status   *status.Status
-	code     string
-	reason   string
-	message  string
-	httpCode int
-	grpcCode codes.Code
-}
-
-func (err *grpcError) Error() string {
-	return fmt.Sprintf(
-		"Code=%s Reason=%s Message=(%v)",
-		err.code,
-		err.reason,
-		err.message,
-	)
-}
-
-func (err *grpcError) Status() *status.Status {
-	return err.status
+	return errToStatus(err, statusConfig{details: exposure(err, false)})
 }
 
-func (err *grpcError) HTTPCode() int {
-	return err.httpCode
-}
-
-func (err *grpcError) GRPCCode() codes.Code {
-	return err.grpcCode
-}
-
-func (err *grpcError) TypeCode() string {
-	return err.code
-}
-
-// Is returns true if any of TypeCoder, HTTPCoder, GRPCCoder are a match between the error and target
-func (err *grpcError) Is(target error) bool {
-	if t, ok := target.(GRPCCoder); ok && err.grpcCode == t.GRPCCode() {
-		return true
-	}
-	if t, ok := target.(HTTPCoder); ok && err.httpCode == t.HTTPCode() {
-		return true
-	}
-	if t, ok := target.(TypeCoder); ok && err.code == t.TypeCode() {
-		return true
-	}
-	return false
-}
-
-// GRPCCode returns the GRPC code for the given error or codes.OK when nil or codes.Unknown otherwise
+// GRPCCode returns the GRPC code for the given error or codes.OK when nil.
+//
+// Errors without GRPCCoder get the GRPC code of the code the classifiers map them onto,
+// codes.Unknown when none of them knows the error.
 func GRPCCode(err error) codes.Code {
 	if err == nil {
 		return ErrOK.GRPCCode()
@@ -152,7 +50,8 @@ func GRPCCode(err error) codes.Code {
 	if errors.As(err, &e) {
 		return e.GRPCCode()
 	}
-	return ErrUnknown.GRPCCode()
+	code, _, _ := Classify(err)
+	return code.GRPCCode()
 }
 
 // SendGRPCError ensures that the error being used is sent with the correct code applied
@@ -164,21 +63,39 @@ func SendGRPCError(err error) error {
 		return nil
 	}
 
-	// Already setup with a grpcCode
-	if _, ok := status.FromError(err); ok {
+	// Statuses made by other packages are sent as they are; the ones wrapping a code
+	// would be sent with the text of the whole chain as message
+	var coder TypeCoder
+	if _, ok := status.FromError(err); ok && !errors.As(err, &coder) {
 		return err
 	}
 
-	s := errToStatus(err)
+	s := errToStatus(err, statusConfig{details: exposure(err, false)})
 
 	return s.Err()
 }
 
-// ReceiveGRPCError recreates the error with the coded Error reapplied
+// SendGRPCErrorContext is SendGRPCError with the message localized in the locales asked
+// for by the client, see LocalesFromContext, and the details exposed to the caller of ctx,
+// see ContextWithTrustedCaller.
+func SendGRPCErrorContext(ctx context.Context, err error) error {
+	var e *AError
+	if !errors.As(err, &e) {
+		return SendGRPCError(err)
+	}
+	return errToStatus(err, statusConfig{locales: LocalesFromContext(ctx), details: exposure(err, IsTrustedCaller(ctx))}).Err()
+}
+
+// ReceiveGRPCError recreates the *AError sent with SendGRPCError from the status error err.
+//
+// The code, reason, message, ID, fields and google.rpc details of the error are restored,
+// and so are its parents when they were sent. A status without ErrorDetail results in an
+// error with the code the GRPC code converts back to, the message of the status and the
+// reason and metadata of its google.rpc.ErrorInfo as reason and fields. Errors that are
+// not statuses are returned by From.
 //
-// Non-nil results can be used as both Error and *status.Status. Methods
-// errors.Is()/errors.As(), and status.Convert()/status.FromError() will
-// continue to work.
+// Codes that are not registered by the client are kept along with the HTTP and GRPC codes
+// sent for them. status.FromError() and status.Convert() keep working on the result.
 //
 // Use in the clients when receiving errors.
 // If err is nil then ReceiveGRPCError returns nil.
@@ -189,99 +106,94 @@ func ReceiveGRPCError(err error) error {
 
 	s, ok := status.FromError(err)
 	if !ok {
-		return &grpcError{
-			status:   s,
-			grpcCode: ErrUnknown.GRPCCode(),
-			httpCode: ErrUnknown.HTTPCode(),
-			code:     ErrUnknown.TypeCode(),
-			reason:   ErrUnknown.Error(),
-			message:  err.Error(),
-		}
+		return From(err)
 	}
-
-	grpcCode := s.Code()
-	httpCode := ErrUnknown.HTTPCode()
-	embedType := codeToError(grpcCode).TypeCode()
-	reason := ErrUnknown.Error()
-
+	var (
+		e    *AError
+		info *errdetails.ErrorInfo
+		rich []proto.Message
+	)
 	for _, detail := range s.Details() {
 		switch d := detail.(type) {
 		case *ErrorDetail:
-			grpcCode = codes.Code(d.GRPCCode)
-			httpCode = int(d.HTTPCode)
-			embedType = d.TypeCode
-			reason = d.Reason
+			e = detailToError(d)
+		case *errdetails.ErrorInfo:
+			info = d
 		default:
+			if isRichDetail(d) {
+				rich = append(rich, d.(proto.Message))
+			}
 		}
 	}
 
-	return &grpcError{
-		status:   s,
-		grpcCode: grpcCode,
-		httpCode: httpCode,
-		code:     embedType,
-		reason:   reason,
-		message:  s.Message(),
+	// Statuses from services that only know the rich error model
+	if e == nil {
+		e = newAError(codeToError(s.Code()), "")
+		e.message = s.Message()
+		if info != nil {
+			e.reason = info.Reason
+			for _, key := range sortedKeys(info.Metadata) {
+				e.fields = append(e.fields, field{key: key, value: info.Metadata[key]})
+			}
+		}
+	}
+	e.details = append(e.details, rich...)
+	e.render()
+	return e
+}
+
+// detailToError rebuilds the error described by d, leaving the ID unset when none was sent.
+func detailToError(d *ErrorDetail) *AError {
+	e := newAError(Code(d.TypeCode), d.Reason)
+	// codes registered by the sender only keep the statuses it sent
+	if !e.code.Registered() {
+		e.grpcCode = codes.Code(d.GRPCCode)
+		e.httpCode = int(d.HTTPCode)
+		if e.httpCode == 0 {
+			e.httpCode = codeToError(e.grpcCode).HTTPCode()
+		}
+	}
+	e.message = d.Message
+	e.id = append(e.id[:0], d.ID...)
+	for _, key := range sortedKeys(d.Fields) {
+		e.fields = append(e.fields, field{key: key, value: d.Fields[key]})
 	}
+	for _, p := range d.Parents {
+		// opaque parents are sent with their message only
+		if p.TypeCode == "" {
+			e.addParent(errors.New(p.Message))
+			continue
+		}
+		e.addParent(detailToError(p))
+	}
+	e.render()
+	return e
 }
 
+// ExtractGRPCError returns the GRPC code and the message of the error received as err, with
+// ok false when err is nil.
 func ExtractGRPCError(err error) (c codes.Code, msg string, ok bool) {
-	grpcErr, _ := ReceiveGRPCError(err).(*grpcError)
-	if grpcErr == nil {
+	e, _ := ReceiveGRPCError(err).(*AError)
+	if e == nil {
 		return codes.OK, "", false
 	}
-	return grpcErr.GRPCCode(), grpcErr.message, true
+	return e.GRPCCode(), e.message, true
 }
 
-// convert a code to a known Error type;
-func codeToError(code codes.Code) Code {
-	switch code {
-	case codes.OK:
-		return ErrOK
-	case codes.Canceled:
-		return ErrCanceled
-	case codes.Unknown:
-		return ErrUnknown
-	case codes.InvalidArgument:
-		return ErrInvalidArgument
-	case codes.DeadlineExceeded:
-		return ErrDeadlineExceeded
-	case codes.NotFound:
-		return ErrNotFound
-	case codes.AlreadyExists:
-		return ErrAlreadyExists
-	case codes.PermissionDenied:
-		return ErrPermissionDenied
-	case codes.ResourceExhausted:
-		return ErrResourceExhausted
-	case codes.FailedPrecondition:
-		return ErrFailedPrecondition
-	case codes.Aborted:
-		return ErrAborted
-	case codes.OutOfRange:
-		return ErrOutOfRange
-	case codes.Unimplemented:
-		return ErrUnimplemented
-	case codes.Internal:
-		return ErrInternal
-	case codes.Unavailable:
-		return ErrUnavailable
-	case codes.DataLoss:
-		return ErrDataLoss
-	case codes.Unauthenticated:
-		return ErrUnauthenticated
-	default:
-		return ErrInternal
-	}
+// statusConfig configures the statuses built by errToStatus.
+type statusConfig struct {
+	locales []string
+	details Detail
 }
 
-// convert an error into a gRPC *status.Status
-func errToStatus(err error) *status.Status {
-	grpcCode := ErrUnknown.GRPCCode()
-	httpCode := ErrUnknown.HTTPCode()
-	typeCode := ErrUnknown.TypeCode()
+func errToStatus(err error, cfg statusConfig) *status.Status {
+	// Errors without codes get the ones of their classification; otherwise Unknown
+	code, reason, _ := Classify(err)
+	grpcCode := code.GRPCCode()
+	httpCode := code.HTTPCode()
+	typeCode := code.TypeCode()
 
-	// Set the grpcCode based on GRPCCoder output; otherwise leave as Unknown
+	// Set the grpcCode based on GRPCCoder output; otherwise leave the classified one
 	var grpcCoder GRPCCoder
 	if errors.As(err, &grpcCoder) {
 		grpcCode = grpcCoder.GRPCCode()
@@ -292,31 +204,152 @@ func errToStatus(err error) *status.Status {
 		return status.New(codes.OK, "")
 	}
 
-	// Set the httpCode based on HTTPCoder output; otherwise leave as Unknown
+	// Set the httpCode based on HTTPCoder output; otherwise leave the classified one
 	var httpCoder HTTPCoder
 	if errors.As(err, &httpCoder) {
 		httpCode = httpCoder.HTTPCode()
 	}
 
-	// Embed the specific error "type"; otherwise leave as "UNKNOWN"
+	// Embed the specific error "type"; otherwise leave the classified one
 	var typeCoder TypeCoder
 	if errors.As(err, &typeCoder) {
 		typeCode = typeCoder.TypeCode()
+		reason = ""
 	}
 
 	errInfo := &ErrorDetail{
+		Reason:   reason,
 		TypeCode: typeCode,
 		GRPCCode: int64(grpcCode),
 		HTTPCode: int64(httpCode),
 	}
 
-	var e AError
-	if ok := errors.As(err, &e); ok {
+	// Embed the identifier so that the error can be correlated with server logs
+	var ider identifier
+	if cfg.details&DetailID != 0 && errors.As(err, &ider) {
+		errInfo.ID = ider.ID()
+	}
+
+	// Embed the fields as strings
+	var fielder Fielder
+	if cfg.details&DetailFields != 0 && errors.As(err, &fielder) {
+		if fields := fielder.Fields(); len(fields) != 0 {
+			errInfo.Fields = make(map[string]string, len(fields))
+			for k, v := range fields {
+				errInfo.Fields[k] = fieldString(v)
+			}
+		}
+	}
+
+	var e *AError
+	if errors.As(err, &e) {
 		errInfo.Reason = e.reason
-		errInfo.Message = e.message
+		if cfg.details&DetailMessage != 0 {
+			errInfo.Message = e.message
+		}
+		if cfg.details&DetailParents != 0 {
+			errInfo.Parents = parentDetails(e.parents, cfg)
+		}
 	}
 
-	s, _ := status.New(grpcCode, err.Error()).WithDetails(errInfo)
+	// Send the standard ErrorInfo as well for the clients that only know the rich error model
+	info := &errdetails.ErrorInfo{
+		Reason:   errorInfoReason(errInfo.Reason),
+		Domain:   *errorDomain.Load(),
+		Metadata: errorInfoMetadata(errInfo.Fields),
+	}
+	if info.Reason == "" {
+		info.Reason = errorInfoReason(typeCode)
+	}
+	if info.Reason == "" {
+		info.Reason = ErrUnknown.TypeCode()
+	}
+	details := []protoadapt.MessageV1{errInfo, info}
+
+	localized := false
+	if e != nil {
+		for _, d := range e.details {
+			switch d.(type) {
+			case *errdetails.DebugInfo:
+				// sent along with the stack below
+				continue
+			case *errdetails.LocalizedMessage:
+				// received along with the error
+				if cfg.details&DetailLocalized == 0 {
+					continue
+				}
+				localized = true
+			default:
+				if cfg.details&DetailRich == 0 {
+					continue
+				}
+			}
+			details = append(details, protoadapt.MessageV1Of(d))
+		}
+		if cfg.details&DetailDebug != 0 {
+			if info := e.debugInfo(); info != nil {
+				details = append(details, info)
+			}
+		}
+	}
+
+	// Send the localized message along, for clients to show it as is
+	if cfg.details&DetailLocalized != 0 && !localized {
+		if locale, message, ok := Localize(err, cfg.locales...); ok {
+			details = append(details, &errdetails.LocalizedMessage{Locale: locale, Message: message})
+		}
+	}
+
+	// The text of the error holds the text of its parents and its stack
+	message := errInfo.Message
+	switch {
+	case cfg.details&DetailText != 0:
+		message = err.Error()
+	case message == "" && errInfo.Reason != "":
+		message = errInfo.Reason
+	case message == "":
+		message = typeCode
+	}
+
+	s, _ := status.New(grpcCode, message).WithDetails(details...)
 
 	return s
 }
+
+// parentDetails returns the details of parents, opaque ones being described by their
+// message only.
+func parentDetails(parents []error, cfg statusConfig) []*ErrorDetail {
+	details := make([]*ErrorDetail, 0, len(parents))
+	for _, parent := range parents {
+		p, ok := parent.(*AError)
+		if !ok {
+			d := &ErrorDetail{}
+			if cfg.details&DetailMessage != 0 {
+				d.Message = parent.Error()
+			}
+			details = append(details, d)
+			continue
+		}
+		d := &ErrorDetail{
+			Reason:   p.reason,
+			TypeCode: p.code.TypeCode(),
+			GRPCCode: int64(p.GRPCCode()),
+			HTTPCode: int64(p.HTTPCode()),
+			Parents:  parentDetails(p.parents, cfg),
+		}
+		if cfg.details&DetailMessage != 0 {
+			d.Message = p.message
+		}
+		if cfg.details&DetailID != 0 {
+			d.ID = string(p.id)
+		}
+		if cfg.details&DetailFields != 0 && len(p.fields) != 0 {
+			d.Fields = make(map[string]string, len(p.fields))
+			for _, f := range p.fields {
+				d.Fields[f.key] = fieldString(f.value)
+			}
+		}
+		details = append(details, d)
+	}
+	return details
+}
@@ -0,0 +1,414 @@
+package aerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCRoundTrip(t *testing.T) {
+	sent := NotFound(fakeReason).
+		WithMessage(fakeMessage).
+		WithField("user_id", 42).
+		WithField("tenant", "acme").
+		Err()
+
+	got := ReceiveGRPCError(SendGRPCErrorContext(ContextWithTrustedCaller(context.Background()), sent))
+
+	if GRPCCode(got) != ErrNotFound.GRPCCode() || TypeCode(got) != ErrNotFound.TypeCode() {
+		t.Errorf("unexpected codes: %s", got)
+	}
+	if ID(got) == "" || ID(got) != ID(sent) {
+		t.Errorf("ID = %q, want %q", ID(got), ID(sent))
+	}
+	fields := Fields(got)
+	if len(fields) != 2 || fields["user_id"] != "42" || fields["tenant"] != "acme" {
+		t.Errorf("Fields() = %v", fields)
+	}
+	var ae *AError
+	if !errors.As(got, &ae) || ae.Reason() != fakeReason || ae.Message() != fakeMessage || !IsNotFound(got) {
+		t.Errorf("received %v", got)
+	}
+	if ae.Error() != string(sent.(*AError).line()) {
+		t.Errorf("Error() = %q, want %q", ae.Error(), string(sent.(*AError).line()))
+	}
+
+	chained := Internal("saving order").
+		WithParent(Wrap(NotFound(fakeReason).WithField("tenant", "acme").Err(), "loading user")).
+		WithParent(errExample).
+		Err()
+	got = ReceiveGRPCError(errToStatus(chained, statusConfig{details: DetailAll}).Err())
+	if got.Error() != string(chained.(*AError).line()) {
+		t.Errorf("Error() = %q, want %q", got.Error(), string(chained.(*AError).line()))
+	}
+	if !errors.Is(got, ErrNotFound) || !IsInternal(got) || Fields(got.(*AError).Causes()[0])["tenant"] != "acme" {
+		t.Errorf("parents not received: %+v", got)
+	}
+	if len(ReceiveGRPCError(SendGRPCError(chained)).(*AError).Causes()) != 0 {
+		t.Error("parents are sent by default")
+	}
+
+	wrapped, _ := status.FromError(SendGRPCError(fmt.Errorf("loading user: %w", chained)))
+	if wrapped.Code() != codes.Internal || strings.Contains(wrapped.Message(), "loading user") || len(wrapped.Details()) == 0 {
+		t.Errorf("wrapped error sent as %v", wrapped)
+	}
+
+	custom, _ := status.New(codes.FailedPrecondition, "").WithDetails(&ErrorDetail{
+		TypeCode: "PAYMENT_OVERDUE",
+		GRPCCode: int64(codes.FailedPrecondition),
+		HTTPCode: 402,
+	})
+	got = ReceiveGRPCError(custom.Err())
+	if TypeCode(got) != "PAYMENT_OVERDUE" || HTTPCode(got) != 402 || GRPCCode(got) != codes.FailedPrecondition {
+		t.Errorf("unregistered code received as %s, %d, %s", TypeCode(got), HTTPCode(got), GRPCCode(got))
+	}
+	if s, _ := status.FromError(SendGRPCError(got)); s.Code() != codes.FailedPrecondition {
+		t.Errorf("unregistered code forwarded as %s", s.Code())
+	}
+
+	plain := ReceiveGRPCError(status.Error(codes.PermissionDenied, "denied"))
+	if !errors.As(plain, &ae) || ae.Code() != ErrPermissionDenied || ae.Message() != "denied" || ID(plain) != "" {
+		t.Errorf("received %v", plain)
+	}
+	if got := ReceiveGRPCError(context.Canceled); !IsCanceled(got) || !errors.Is(got, context.Canceled) {
+		t.Errorf("received %v", got)
+	}
+	if c, msg, ok := ExtractGRPCError(SendGRPCError(sent)); !ok || c != codes.NotFound || msg != fakeMessage {
+		t.Errorf("ExtractGRPCError() = %v, %q, %v", c, msg, ok)
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestServerInterceptors(t *testing.T) {
+	var hooked []error
+	unary := UnaryServerInterceptor(
+		ServerHooks(func(_ context.Context, method string, err error) {
+			if method != "/svc/Method" {
+				t.Errorf("hook called for %q", method)
+			}
+			hooked = append(hooked, err)
+		}),
+		ServerDetails(DetailID),
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+	call := func(ctx context.Context, handler grpc.UnaryHandler) error {
+		_, err := unary(ctx, nil, info, handler)
+		return err
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, tc := range []struct {
+		name    string
+		ctx     context.Context
+		handler grpc.UnaryHandler
+		code    codes.Code
+	}{
+		{"aerror", context.Background(), func(context.Context, any) (any, error) {
+			return nil, NotFound(fakeReason).WithMessage(fakeMessage).Err()
+		}, codes.NotFound},
+		{"context", context.Background(), func(context.Context, any) (any, error) {
+			return nil, context.DeadlineExceeded
+		}, codes.DeadlineExceeded},
+		{"done context", canceled, func(context.Context, any) (any, error) {
+			return nil, io.ErrUnexpectedEOF
+		}, codes.Canceled},
+		{"status", context.Background(), func(context.Context, any) (any, error) {
+			return nil, status.Error(codes.Aborted, "aborted")
+		}, codes.Aborted},
+		{"panic", context.Background(), func(context.Context, any) (any, error) {
+			panic("boom")
+		}, codes.Internal},
+	} {
+		s, ok := status.FromError(call(tc.ctx, tc.handler))
+		if !ok || s.Code() != tc.code {
+			t.Errorf("%s: got status %v", tc.name, s)
+		}
+	}
+	if _, err := unary(context.Background(), nil, info, func(context.Context, any) (any, error) { return "ok", nil }); err != nil {
+		t.Errorf("successful call failed: %v", err)
+	}
+
+	if len(hooked) != 5 {
+		t.Fatalf("hooks called %d times", len(hooked))
+	}
+	var panicked *AError
+	if !errors.As(hooked[4], &panicked) || len(panicked.Causes()) != 1 || panicked.Causes()[0].Error() != "boom" ||
+		!strings.Contains(panicked.Stack(), "TestServerInterceptors") {
+		t.Errorf("panic reported as %v", hooked[4])
+	}
+	if top := panicked.StackTrace()[0].Function; top != "runtime.gopanic" {
+		t.Errorf("stack of the panic starts at %s", top)
+	}
+	s, _ := status.FromError(call(context.Background(), func(context.Context, any) (any, error) { panic("boom") }))
+	if strings.Contains(fmt.Sprint(s.Proto()), "boom") {
+		t.Errorf("panic value sent to an untrusted caller: %v", s)
+	}
+
+	got := ReceiveGRPCError(call(context.Background(), func(context.Context, any) (any, error) {
+		return nil, NotFound(fakeReason).WithMessage(fakeMessage).WithField("tenant", "acme").Err()
+	}))
+	if ID(got) == "" || len(Fields(got)) != 0 {
+		t.Errorf("details are not selected: ID %q, fields %v", ID(got), Fields(got))
+	}
+
+	stream := StreamServerInterceptor()
+	err := stream(nil, testServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"},
+		func(any, grpc.ServerStream) error {
+			var m map[string]int
+			m["boom"]++
+			return nil
+		})
+	if s, _ := status.FromError(err); s.Code() != codes.Internal {
+		t.Errorf("stream panic sent as %v", s)
+	}
+}
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (healthServer) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return nil, NotFound(fakeReason).WithMessage(fakeMessage).WithField("tenant", "acme").Err()
+}
+
+func (healthServer) Watch(_ *grpc_health_v1.HealthCheckRequest, ws grpc_health_v1.Health_WatchServer) error {
+	if err := ws.Send(&grpc_health_v1.HealthCheckResponse{}); err != nil {
+		return err
+	}
+	return Unavailable(fakeReason).Err()
+}
+
+func TestClientInterceptors(t *testing.T) {
+	lis := bufconn.Listen(1 << 16)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(ServerTrust(func(context.Context) bool { return true }))),
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, healthServer{})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	fields := Fields(err)
+	if !IsNotFound(err) || fields["tenant"] != "acme" || fields["grpc.method"] != "/grpc.health.v1.Health/Check" ||
+		fields["grpc.peer"] != "bufconn" {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv() = %v", err)
+	}
+	_, err = stream.Recv()
+	if fields := Fields(err); !IsUnavailable(err) || fields["grpc.method"] != "/grpc.health.v1.Health/Watch" ||
+		fields["grpc.peer"] != "bufconn" {
+		t.Errorf("Recv() error = %v", err)
+	}
+}
+
+func TestRichDetails(t *testing.T) {
+	SetErrorDomain("orders.example.com")
+	defer SetErrorDomain("")
+
+	sent := InvalidArgument("INVALID_ORDER").
+		WithFieldViolation("quantity", "must be positive").
+		WithFieldViolation("sku", "unknown").
+		WithRetryDelay(2*time.Second).
+		WithQuotaViolation("tenant:acme", "daily orders").
+		WithPreconditionViolation("TOS", "tenant:acme", "terms not accepted").
+		WithResourceInfo("order", "orders/42", "acme", "").
+		WithHelpLink("API docs", "https://example.com/docs").
+		WithDebugInfo("validation failed").
+		WithField("tenant", "acme").
+		WithStack().
+		Err()
+
+	s := errToStatus(sent, statusConfig{details: DetailPublic | DetailFields})
+	var info *errdetails.ErrorInfo
+	for _, d := range s.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.DebugInfo:
+			t.Error("DebugInfo is sent by default")
+		}
+	}
+	if info == nil || info.Reason != "INVALID_ORDER" || info.Domain != "orders.example.com" || info.Metadata["tenant"] != "acme" {
+		t.Errorf("ErrorInfo = %v", info)
+	}
+
+	bad := InvalidArgument("bad_email").WithFieldViolation("email", "invalid").Err()
+	for _, wrapped := range []error{Wrap(bad, "creating user"), WrapCode(bad, ErrFailedPrecondition, ""), fmt.Errorf("creating user: %w", bad)} {
+		s, _ := status.FromError(SendGRPCError(wrapped))
+		if len(s.Details()) != 3 {
+			t.Errorf("%v sent with details %v", wrapped, s.Details())
+		}
+	}
+	wrapped := Wrap(bad, "creating user").(*AError)
+	wrapped.WithFieldViolation("name", "missing")
+	if d := bad.(*AError).Details()[0].(*errdetails.BadRequest); len(d.FieldViolations) != 1 {
+		t.Errorf("details of the wrapped error changed: %v", d)
+	}
+
+	got := ReceiveGRPCError(errToStatus(sent, statusConfig{details: DetailAll}).Err()).(*AError)
+	var (
+		badRequest *errdetails.BadRequest
+		debug      *errdetails.DebugInfo
+	)
+	for _, d := range got.Details() {
+		switch d := d.(type) {
+		case *errdetails.BadRequest:
+			badRequest = d
+		case *errdetails.DebugInfo:
+			debug = d
+		}
+	}
+	if len(got.Details()) != 7 || badRequest == nil || len(badRequest.FieldViolations) != 2 {
+		t.Errorf("Details() = %v", got.Details())
+	}
+	if debug == nil || debug.Detail != "validation failed" || !strings.Contains(strings.Join(debug.StackEntries, "\n"), "TestRichDetails") {
+		t.Errorf("DebugInfo = %v", debug)
+	}
+
+	for reason, want := range map[string]string{
+		"INVALID_ORDER":         "INVALID_ORDER",
+		"order not found":       "ORDER_NOT_FOUND",
+		"404: user-missing!":    "USER_MISSING",
+		"é":                     "",
+		strings.Repeat("a", 70): strings.Repeat("A", 63),
+	} {
+		if got := errorInfoReason(reason); got != want {
+			t.Errorf("errorInfoReason(%q) = %q, want %q", reason, got, want)
+		}
+	}
+	metadata := errorInfoMetadata(map[string]string{
+		"grpc.method": "/svc/Get", "Tenant": "acme", "tenant": "other", "9lives": "cat", "x": "short",
+		strings.Repeat("k", 70): "long",
+	})
+	want := map[string]string{"grpc_method": "/svc/Get", "tenant": "acme", "lives": "cat", strings.Repeat("k", 64): "long"}
+	if !reflect.DeepEqual(metadata, want) {
+		t.Errorf("errorInfoMetadata() = %v, want %v", metadata, want)
+	}
+	SetErrorDomain("")
+	s = errToStatus(Internal("").Err(), statusConfig{})
+	for _, d := range s.Details() {
+		if d, ok := d.(*errdetails.ErrorInfo); ok && (d.Reason != "INTERNAL" || d.Domain == "") {
+			t.Errorf("ErrorInfo = %v", d)
+		}
+	}
+
+	standard, _ := status.New(codes.ResourceExhausted, "too many orders").WithDetails(
+		&errdetails.ErrorInfo{Reason: "ORDER_QUOTA", Domain: "orders.example.com", Metadata: map[string]string{"limit": "10"}},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "tenant:acme"}}},
+	)
+	got = ReceiveGRPCError(standard.Err()).(*AError)
+	if got.Code() != ErrResourceExhausted || got.Reason() != "ORDER_QUOTA" || got.Message() != "too many orders" ||
+		Fields(got)["limit"] != "10" || len(got.Details()) != 1 {
+		t.Errorf("received %v with %v", got, got.Details())
+	}
+}
+
+func TestExposure(t *testing.T) {
+	err := Internal("SAVE_FAILED").
+		WithMessage("order could not be saved").
+		WithParent(errors.New("pq: password authentication failed for user \"orders\"")).
+		WithField("table", "orders").
+		WithStack().
+		Err()
+
+	s, _ := status.FromError(SendGRPCError(err))
+	if s.Message() != "order could not be saved" {
+		t.Errorf("status message = %q", s.Message())
+	}
+	got := ReceiveGRPCError(s.Err()).(*AError)
+	if len(got.Causes()) != 0 || len(Fields(got)) != 0 || got.ID() != ID(err) || got.Reason() != "SAVE_FAILED" {
+		t.Errorf("untrusted caller received %v", got)
+	}
+
+	trusted := ContextWithTrustedCaller(context.Background())
+	s, _ = status.FromError(SendGRPCErrorContext(trusted, err))
+	if s.Message() != err.Error() {
+		t.Errorf("status message = %q", s.Message())
+	}
+	got = ReceiveGRPCError(s.Err()).(*AError)
+	if len(got.Causes()) != 1 || Fields(got)["table"] != "orders" {
+		t.Errorf("trusted caller received %v", got)
+	}
+
+	SetExposurePolicy(ExposeTrusted(DetailAll, ExposeByCode(map[Code]Detail{ErrInternal: DetailID}, DetailPublic)))
+	defer SetExposurePolicy(nil)
+	s, _ = status.FromError(SendGRPCError(err))
+	if got := ReceiveGRPCError(s.Err()).(*AError); s.Message() != "SAVE_FAILED" || got.Message() != "" || got.ID() != ID(err) {
+		t.Errorf("INTERNAL error exposed as %q: %v", s.Message(), got)
+	}
+	s, _ = status.FromError(SendGRPCError(New(ErrInternalServerError, "SAVE_FAILED").WithMessage(fakeMessage).Err()))
+	if s.Message() != "SAVE_FAILED" {
+		t.Errorf("INTERNAL_SERVER_ERROR error exposed as %q", s.Message())
+	}
+	SetExposurePolicy(ExposeByCode(map[Code]Detail{ErrInternalServerError: DetailID}, DetailPublic))
+	s, _ = status.FromError(SendGRPCError(err))
+	if s.Message() != "SAVE_FAILED" {
+		t.Errorf("INTERNAL error exposed as %q by a rule on INTERNAL_SERVER_ERROR", s.Message())
+	}
+
+	SetDebugExposure(true)
+	defer SetDebugExposure(false)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	WriteHTTPError(w, r, err)
+	var body HTTPErrorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Parents) != 1 || !strings.Contains(body.Parents[0].Message, "pq:") || len(body.Stack) == 0 {
+		t.Errorf("debug exposure wrote %s", w.Body)
+	}
+
+	SetDebugExposure(false)
+	w = httptest.NewRecorder()
+	WriteHTTPError(w, r, err)
+	if strings.Contains(w.Body.String(), "pq:") || strings.Contains(w.Body.String(), "stack") {
+		t.Errorf("untrusted caller was written %s", w.Body)
+	}
+}
@@ -1,90 +1,34 @@
 package aerrors
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 )
 
 type HTTPCoder interface {
 	HTTPCode() int
 }
 
-// nolint:gocyclo
-func (err Code) HTTPCode() int {
-	switch err {
-	// GRPC Errors
-	case ErrOK:
-		return http.StatusOK
-	case ErrCanceled:
-		return http.StatusRequestTimeout
-	case ErrUnknown:
-		return http.StatusNotExtended
-	case ErrInvalidArgument:
-		return http.StatusBadRequest
-	case ErrDeadlineExceeded:
-		return http.StatusGatewayTimeout
-	case ErrNotFound:
-		return http.StatusNotFound
-	case ErrAlreadyExists:
-		return http.StatusConflict
-	case ErrPermissionDenied:
-		return http.StatusForbidden
-	case ErrResourceExhausted:
-		return http.StatusTooManyRequests
-	case ErrFailedPrecondition:
-		return http.StatusBadRequest
-	case ErrAborted:
-		return http.StatusConflict
-	case ErrOutOfRange:
-		return http.StatusUnprocessableEntity
-	case ErrUnimplemented:
-		return http.StatusNotImplemented
-	case ErrInternal:
-		return http.StatusInternalServerError
-	case ErrUnavailable:
-		return http.StatusServiceUnavailable
-	case ErrDataLoss:
-		return http.StatusInternalServerError
-	case ErrUnauthenticated:
-		return http.StatusUnauthorized
-
-	// HTTP Errors
-	case ErrBadRequest:
-		return http.StatusBadRequest
-	case ErrUnauthorized:
-		return http.StatusUnauthorized
-	case ErrForbidden:
-		return http.StatusForbidden
-	case ErrMethodNotAllowed:
-		return http.StatusMethodNotAllowed
-	case ErrRequestTimeout:
-		return http.StatusRequestTimeout
-	case ErrConflict:
-		return http.StatusConflict
-	case ErrImATeapot:
-		return 418 // teapot support
-	case ErrUnprocessableEntity:
-		return http.StatusUnprocessableEntity
-	case ErrTooManyRequests:
-		return http.StatusTooManyRequests
-	case ErrUnavailableForLegalReasons:
-		return http.StatusUnavailableForLegalReasons
-	case ErrInternalServerError:
-		return http.StatusInternalServerError
-	case ErrNotImplemented:
-		return http.StatusNotImplemented
-	case ErrBadGateway:
-		return http.StatusBadGateway
-	case ErrServiceUnavailable:
-		return http.StatusServiceUnavailable
-	case ErrGatewayTimeout:
-		return http.StatusGatewayTimeout
-	default:
-		return http.StatusInternalServerError
+// HTTPCode returns the HTTP status of the error's Code.
+func (err *AError) HTTPCode() int {
+	if err == nil {
+		return ErrOK.HTTPCode()
 	}
+	err.assertLive()
+	if err.httpCode != 0 {
+		return err.httpCode
+	}
+	return err.code.HTTPCode()
 }
 
-// HTTPCode returns the HTTP status for the given error or http.StatusOK when nil or http.StatusNotExtended otherwise
+// HTTPCode returns the HTTP status for the given error or http.StatusOK when nil.
+//
+// Errors without HTTPCoder get the status of the code the classifiers map them onto,
+// http.StatusNotExtended when none of them knows the error.
 func HTTPCode(err error) int {
 	if err == nil {
 		return ErrOK.HTTPCode()
@@ -94,5 +38,177 @@ func HTTPCode(err error) int {
 	if errors.As(err, &e) {
 		return e.HTTPCode()
 	}
-	return ErrUnknown.HTTPCode()
+	code, _, _ := Classify(err)
+	return code.HTTPCode()
+}
+
+// HTTPErrorBody is the JSON body written by WriteHTTPError.
+type HTTPErrorBody struct {
+	Code             string            `json:"code"`
+	Reason           string            `json:"reason,omitempty"`
+	Message          string            `json:"message,omitempty"`
+	ID               string            `json:"id,omitempty"`
+	Fields           map[string]string `json:"fields,omitempty"`
+	LocalizedMessage *LocalizedMessage `json:"localized_message,omitempty"`
+	// Parents are the parents of the error, the ones without code only having a message.
+	Parents []*HTTPErrorBody `json:"parents,omitempty"`
+	Stack   []Frame          `json:"stack,omitempty"`
+}
+
+// LocalizedMessage is a message in the locale asked for by the client.
+type LocalizedMessage struct {
+	Locale  string `json:"locale"`
+	Message string `json:"message"`
+}
+
+// NewHTTPErrorBody returns the body describing the given details of err, with its message
+// localized in the first of locales the catalog has it in.
+func NewHTTPErrorBody(err error, details Detail, locales ...string) *HTTPErrorBody {
+	body := &HTTPErrorBody{Code: TypeCode(err)}
+	if details&DetailID != 0 {
+		body.ID = ID(err)
+	}
+	var fielder Fielder
+	if details&DetailFields != 0 && errors.As(err, &fielder) {
+		if fields := fielder.Fields(); len(fields) != 0 {
+			body.Fields = make(map[string]string, len(fields))
+			for k, v := range fields {
+				body.Fields[k] = fieldString(v)
+			}
+		}
+	}
+	var e *AError
+	if errors.As(err, &e) {
+		body.Reason = e.reason
+		if details&DetailMessage != 0 {
+			body.Message = e.message
+		}
+		if details&DetailParents != 0 {
+			body.Parents = parentBodies(e.parents, details)
+		}
+		if details&DetailDebug != 0 {
+			body.Stack = e.StackTrace()
+		}
+	}
+	if details&DetailLocalized != 0 {
+		if locale, message, ok := Localize(err, locales...); ok {
+			body.LocalizedMessage = &LocalizedMessage{Locale: locale, Message: message}
+		}
+	}
+	return body
+}
+
+func parentBodies(parents []error, details Detail) []*HTTPErrorBody {
+	bodies := make([]*HTTPErrorBody, 0, len(parents))
+	for _, parent := range parents {
+		if _, ok := parent.(*AError); ok {
+			bodies = append(bodies, NewHTTPErrorBody(parent, details&^DetailLocalized))
+			continue
+		}
+		body := &HTTPErrorBody{}
+		if details&DetailMessage != 0 {
+			body.Message = parent.Error()
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies
+}
+
+// WriteHTTPError writes err as a JSON HTTPErrorBody with the HTTP status of its code.
+//
+// The details written are the ones the exposure policy exposes to the caller, trusted when
+// the context of r was marked with ContextWithTrustedCaller. The message is localized in
+// the locales of the context of r set with ContextWithLocales, or else in the ones of its
+// Accept-Language header.
+func WriteHTTPError(w http.ResponseWriter, r *http.Request, err error) {
+	locales, ok := r.Context().Value(localesKey{}).([]string)
+	if !ok {
+		locales = AcceptLanguage(r.Header.Get("Accept-Language"))
+	}
+	body := NewHTTPErrorBody(err, exposure(err, IsTrustedCaller(r.Context())), locales...)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if body.LocalizedMessage != nil {
+		w.Header().Set("Content-Language", body.LocalizedMessage.Locale)
+	}
+	w.WriteHeader(HTTPCode(err))
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// maxHTTPErrorBody bounds the bodies read by ReadHTTPError.
+const maxHTTPErrorBody = 1 << 20
+
+// ReadHTTPError recreates the *AError written with WriteHTTPError from the response resp,
+// closing its body. It returns nil when the status of resp is not an error status.
+//
+// The code, reason, message, ID, fields and localized message of the error are restored,
+// and so are its parents when they were written. A body that is not an HTTPErrorBody
+// results in an error with the code registered first for the status, or ErrUnknown, and
+// the status text as message. Codes that are not registered by the client are kept along
+// with the status of resp.
+//
+// Use in the clients when receiving errors.
+func ReadHTTPError(resp *http.Response) error {
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	var body HTTPErrorBody
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPErrorBody))
+	if err != nil || json.Unmarshal(data, &body) != nil || body.Code == "" {
+		e := newAError(statusToCode(resp.StatusCode), "")
+		e.message = http.StatusText(resp.StatusCode)
+		if err != nil {
+			e.addParent(err)
+		}
+		e.render()
+		return e
+	}
+	e := bodyToError(&body)
+	if !e.code.Registered() {
+		e.httpCode = resp.StatusCode
+		e.grpcCode = statusToCode(resp.StatusCode).GRPCCode()
+	}
+	if body.LocalizedMessage != nil {
+		e.details = append(e.details, &errdetails.LocalizedMessage{
+			Locale:  body.LocalizedMessage.Locale,
+			Message: body.LocalizedMessage.Message,
+		})
+	}
+	e.render()
+	return e
+}
+
+// bodyToError rebuilds the error described by body, leaving the ID unset when none was
+// written.
+func bodyToError(body *HTTPErrorBody) *AError {
+	e := newAError(Code(body.Code), body.Reason)
+	e.message = body.Message
+	e.id = append(e.id[:0], body.ID...)
+	for _, key := range sortedKeys(body.Fields) {
+		e.fields = append(e.fields, field{key: key, value: body.Fields[key]})
+	}
+	for _, p := range body.Parents {
+		// opaque parents are written with their message only
+		if p.Code == "" {
+			e.addParent(errors.New(p.Message))
+			continue
+		}
+		e.addParent(bodyToError(p))
+	}
+	e.render()
+	return e
+}
+
+// statusToCode returns the canonical code registered first for the HTTP status, ErrUnknown
+// when there is none.
+func statusToCode(status int) Code {
+	reg := registry.Load()
+	for _, code := range reg.order {
+		if info := reg.codes[code]; info.httpCode == status && info.family == code {
+			return code
+		}
+	}
+	return ErrUnknown
 }
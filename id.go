@@ -0,0 +1,164 @@
+package aerrors
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// IDGenerator generates the identifiers that Err() gives to every AError.
+type IDGenerator interface {
+	// AppendID appends a new identifier to dst and returns the extended buffer.
+	AppendID(dst []byte) []byte
+}
+
+type idGeneratorHolder struct {
+	g IDGenerator
+}
+
+var idGenerator atomic.Pointer[idGeneratorHolder]
+
+func init() {
+	SetIDGenerator(ULIDGenerator())
+}
+
+// SetIDGenerator replaces the generator used for new errors; nil turns identifiers off.
+//
+// ULIDs are generated by default.
+func SetIDGenerator(g IDGenerator) {
+	idGenerator.Store(&idGeneratorHolder{g: g})
+}
+
+// ID returns the identifier of the given error or "" when it has none
+func ID(err error) string {
+	var e identifier
+	if errors.As(err, &e) {
+		return e.ID()
+	}
+	return ""
+}
+
+type identifier interface {
+	ID() string
+}
+
+// ID returns the identifier generated for the error by Err().
+//
+// The returned string shares memory with err and must not be kept after Release.
+func (err *AError) ID() string {
+	if err == nil {
+		return ""
+	}
+	err.assertLive()
+	return BytesToString(err.id)
+}
+
+func (err *AError) generateID() {
+	if len(err.id) != 0 {
+		return
+	}
+	if h := idGenerator.Load(); h.g != nil {
+		err.id = h.g.AppendID(err.id[:0])
+	}
+}
+
+type ulidGenerator struct{}
+
+// ULIDGenerator returns a generator of ULIDs: 26 characters, sortable by creation time.
+//
+// Generating an ID costs a read of the monotonic clock and a random number, most of the
+// cost of Err() for errors without stack; use SetIDGenerator(nil) where that matters. The
+// 80 bits following the timestamp are made of 16 bits of sub-millisecond time and 64
+// random bits.
+//
+// See https://github.com/ulid/spec
+func ULIDGenerator() IDGenerator {
+	return ulidGenerator{}
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// clockStart anchors now to the wall clock, so that it only reads the monotonic clock,
+// cheaper than time.Now.
+var (
+	clockStart   = time.Now()
+	clockStartMs = clockStart.UnixMilli()
+)
+
+// now returns the current Unix time in milliseconds, and the nanoseconds elapsed since.
+func now() (ms, ns uint64) {
+	elapsed := time.Since(clockStart)
+	return uint64(clockStartMs + elapsed.Milliseconds()), uint64(elapsed % time.Millisecond)
+}
+
+func (ulidGenerator) AppendID(dst []byte) []byte {
+	var out [26]byte
+	ms, ns := now()
+	// 48 bits of time in 10 characters of 5 bits, then 80 bits in 16
+	for i := 9; i >= 0; i-- {
+		out[i] = crockford[ms&0x1f]
+		ms >>= 5
+	}
+	r := rand.Uint64()
+	for i := 25; i >= 14; i-- {
+		out[i] = crockford[r&0x1f]
+		r >>= 5
+	}
+	r = r | ns<<4
+	for i := 13; i >= 10; i-- {
+		out[i] = crockford[r&0x1f]
+		r >>= 5
+	}
+	return append(dst, out[:]...)
+}
+
+type uuidV7Generator struct{}
+
+// UUIDv7Generator returns a generator of version 7 UUIDs in their canonical 36 character form.
+//
+// See https://www.rfc-editor.org/rfc/rfc9562#name-uuid-version-7
+func UUIDv7Generator() IDGenerator {
+	return uuidV7Generator{}
+}
+
+func (uuidV7Generator) AppendID(dst []byte) []byte {
+	var id [16]byte
+	ms, _ := now()
+	binary.BigEndian.PutUint16(id[0:], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:], uint32(ms))
+	binary.BigEndian.PutUint16(id[6:], uint16(rand.Uint32()))
+	binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	id[6] = id[6]&0x0f | 0x70 // version 7
+	id[8] = id[8]&0x3f | 0x80 // variant 10
+
+	var out [36]byte
+	hex.Encode(out[0:8], id[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], id[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], id[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], id[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], id[10:])
+	return append(dst, out[:]...)
+}
+
+type sequenceGenerator struct {
+	prefix string
+	n      atomic.Uint64
+}
+
+// SequenceGenerator returns a deterministic generator of "<prefix><n>" identifiers
+// counting from 1, meant for tests.
+func SequenceGenerator(prefix string) IDGenerator {
+	return &sequenceGenerator{prefix: prefix}
+}
+
+func (g *sequenceGenerator) AppendID(dst []byte) []byte {
+	return strconv.AppendUint(append(dst, g.prefix...), g.n.Add(1), 10)
+}
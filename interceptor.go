@@ -0,0 +1,227 @@
+package aerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ServerOption configures the interceptors returned by UnaryServerInterceptor and
+// StreamServerInterceptor.
+type ServerOption func(*serverConfig)
+
+// ServerHook is called with the error of every failed call, before it is sent, such as to
+// log or report it. Recovered panics are given as INTERNAL AErrors carrying the stack of the
+// panic, with the value of the panic as parent.
+type ServerHook func(ctx context.Context, fullMethod string, err error)
+
+type serverConfig struct {
+	recovery bool
+	hooks    []ServerHook
+	details  ExposurePolicy
+	trust    func(ctx context.Context) bool
+}
+
+// ServerRecovery turns the recovery of the panics of handlers on or off, on by default.
+func ServerRecovery(enabled bool) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.recovery = enabled
+	}
+}
+
+// ServerHooks adds hooks called with the errors of failed calls.
+func ServerHooks(hooks ...ServerHook) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.hooks = append(cfg.hooks[:len(cfg.hooks):len(cfg.hooks)], hooks...)
+	}
+}
+
+// ServerDetails selects the details sent with the errors, replacing the exposure policy set
+// with SetExposurePolicy.
+func ServerDetails(details Detail) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.details = details
+	}
+}
+
+// ServerTrust sets the function telling whether the caller of a call is trusted, and is sent
+// the details the exposure policy exposes to trusted callers. Callers are otherwise trusted
+// when the context of the call was marked with ContextWithTrustedCaller.
+func ServerTrust(trust func(ctx context.Context) bool) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.trust = trust
+	}
+}
+
+func newServerConfig(opts []ServerOption) *serverConfig {
+	cfg := &serverConfig{recovery: true, trust: IsTrustedCaller}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// UnaryServerInterceptor returns an interceptor sending the errors of unary handlers the
+// way SendGRPCErrorContext does, so that handlers do not have to.
+//
+// Errors without code are classified, or else mapped onto the error of the context of the
+// call when it is done. Statuses made by other packages are sent as they are.
+func UnaryServerInterceptor(opts ...ServerOption) grpc.UnaryServerInterceptor {
+	cfg := newServerConfig(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if cfg.recovery {
+			defer func() {
+				if r := recover(); r != nil {
+					err = cfg.send(ctx, info.FullMethod, panicError(r))
+				}
+			}()
+		}
+		resp, err = handler(ctx, req)
+		if err != nil {
+			err = cfg.send(ctx, info.FullMethod, err)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming handlers.
+func StreamServerInterceptor(opts ...ServerOption) grpc.StreamServerInterceptor {
+	cfg := newServerConfig(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		if cfg.recovery {
+			defer func() {
+				if r := recover(); r != nil {
+					err = cfg.send(ss.Context(), info.FullMethod, panicError(r))
+				}
+			}()
+		}
+		err = handler(srv, ss)
+		if err != nil {
+			err = cfg.send(ss.Context(), info.FullMethod, err)
+		}
+		return err
+	}
+}
+
+// send calls the hooks with err and returns it as a status error.
+func (cfg *serverConfig) send(ctx context.Context, fullMethod string, err error) error {
+	// a handler failing on a done context may return any error of its dependencies
+	if ctxErr := ctx.Err(); ctxErr != nil && TypeCode(err) == ErrUnknown.TypeCode() {
+		code, reason, _ := Classify(ctxErr)
+		err = New(code, reason).WithParent(err).Err()
+	}
+	for _, hook := range cfg.hooks {
+		hook(ctx, fullMethod, err)
+	}
+
+	var coder TypeCoder
+	if _, ok := status.FromError(err); ok && !errors.As(err, &coder) {
+		return err
+	}
+	details := exposure(err, cfg.trust(ctx))
+	if cfg.details != nil {
+		details = cfg.details.Exposure(Code(TypeCode(err)), cfg.trust(ctx))
+	}
+	return errToStatus(err, statusConfig{locales: LocalesFromContext(ctx), details: details}).Err()
+}
+
+// panicError returns an INTERNAL error for the recovered value r, with the stack of the
+// panic. It must be called by the deferred function that recovered r.
+//
+// The value of the panic is kept as the parent of the error, only sent to the callers
+// the parents are exposed to.
+func panicError(r any) error {
+	e := newAError(ErrInternal, "panic")
+	e.message = "internal error"
+	parent, ok := r.(error)
+	if !ok {
+		parent = fmt.Errorf("%v", r)
+	}
+	e.addParent(parent)
+	// recovered panics always carry a stack, whatever the stack policy
+	e.policy = StackAlways
+	// skip the deferred function and the function it was deferred by, leaving
+	// runtime.gopanic at the top
+	e.captureStack(2, nil)
+	return e.Err()
+}
+
+// UnaryClientInterceptor returns an interceptor applying ReceiveGRPCError to the errors of
+// unary calls, with the full method name and the address of the peer added as the
+// "grpc.method" and "grpc.peer" fields.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		p := &peer.Peer{}
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(p))...)
+		return receive(err, method, p)
+	}
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streaming calls, applied to the
+// errors of the stream as well. The io.EOF ending streams is returned as is.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, receive(err, method, nil)
+		}
+		return &clientStream{ClientStream: cs, method: method}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	method string
+}
+
+// receive is the receive function of the stream, taking the peer from the context of the
+// stream as the one given to grpc.Peer is only set once the stream is done.
+func (s *clientStream) receive(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	p, _ := peer.FromContext(s.Context())
+	return receive(err, s.method, p)
+}
+
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	return md, s.receive(err)
+}
+
+func (s *clientStream) CloseSend() error {
+	return s.receive(s.ClientStream.CloseSend())
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return s.receive(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return s.receive(s.ClientStream.RecvMsg(m))
+}
+
+// receive applies ReceiveGRPCError to err and adds the method and the peer, when known, to
+// its fields.
+func receive(err error, method string, p *peer.Peer) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	received := ReceiveGRPCError(err)
+	e, ok := received.(*AError)
+	if !ok {
+		return received
+	}
+	e.WithField("grpc.method", method)
+	if p != nil && p.Addr != nil {
+		e.WithField("grpc.peer", p.Addr.String())
+	}
+	e.render()
+	return e
+}
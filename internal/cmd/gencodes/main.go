@@ -0,0 +1,110 @@
+// Command gencodes generates a constructor, a formatted constructor and a predicate for
+// every Code constant declared in a file, so that they cannot drift apart from the codes.
+//
+// Usage:
+//
+//	gencodes -in types.go -out codes_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type code struct {
+	name    string // name of the constant, such as ErrNotFound
+	comment string // trailing comment of the constant
+}
+
+func main() {
+	in := flag.String("in", "types.go", "file declaring the Code constants")
+	out := flag.String("out", "codes_gen.go", "file to generate")
+	flag.Parse()
+
+	codes, pkg, err := parse(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(pkg, filepath.Base(*in), codes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parse returns the Code constants named "Err*" declared in file, except ErrOK.
+func parse(file string) ([]code, string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, "", err
+	}
+	var codes []code
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if t, ok := vs.Type.(*ast.Ident); !ok || t.Name != "Code" {
+				continue
+			}
+			for _, name := range vs.Names {
+				if !strings.HasPrefix(name.Name, "Err") || name.Name == "ErrOK" {
+					continue
+				}
+				c := code{name: name.Name}
+				if vs.Comment != nil {
+					c.comment = strings.TrimSpace(vs.Comment.Text())
+				}
+				codes = append(codes, c)
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return nil, "", fmt.Errorf("no Code constant found in %s", file)
+	}
+	return codes, f.Name.Name, nil
+}
+
+func generate(pkg, source string, codes []code) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gencodes from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n\nimport \"fmt\"\n", pkg)
+	for _, c := range codes {
+		fn := strings.TrimPrefix(c.name, "Err")
+		mapping := ""
+		if c.comment != "" {
+			mapping = "\n//\n// " + c.comment
+		}
+		fmt.Fprintf(&b, `
+// %[1]s returns a Builder for an error with the code %[2]s.%[3]s
+func %[1]s(reason string) Builder {
+	return New(%[2]s, reason)
+}
+
+// %[1]sf is %[1]s with the reason formatted according to a format specifier.
+func %[1]sf(format string, args ...any) Builder {
+	return New(%[2]s, fmt.Sprintf(format, args...))
+}
+
+// Is%[1]s reports whether the first code of the chain of err is in the family of %[2]s.
+// Use errors.Is(err, %[2]s) to match any code of the chain.
+func Is%[1]s(err error) bool {
+	return IsCode(err, %[2]s)
+}
+`, fn, c.name, mapping)
+	}
+	return format.Source(b.Bytes())
+}
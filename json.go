@@ -0,0 +1,88 @@
+package aerrors
+
+import (
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+)
+
+// jsonError is the JSON form of an AError; parents that are not AErrors only carry a message.
+type jsonError struct {
+	Code Code `json:"code,omitempty"`
+	// HTTPCode and GRPCCode are only set for the received codes that are not registered
+	HTTPCode int            `json:"http_code,omitempty"`
+	GRPCCode codes.Code     `json:"grpc_code,omitempty"`
+	Reason   string         `json:"reason,omitempty"`
+	Message  string         `json:"message,omitempty"`
+	ID       string         `json:"id,omitempty"`
+	Fields   map[string]any `json:"fields,omitempty"`
+	Stack    []Frame        `json:"stack,omitempty"`
+	Parents  []*jsonError   `json:"parents,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// The code, reason, message, ID, fields, stack and every parent are encoded. Parents
+// that are not AErrors are encoded with their message only.
+func (err *AError) MarshalJSON() ([]byte, error) {
+	err.assertLive()
+	return json.Marshal(err.toJSON())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reversing MarshalJSON.
+//
+// Parents that were not AErrors are decoded as plain errors holding their message.
+func (err *AError) UnmarshalJSON(data []byte) error {
+	var j jsonError
+	if e := json.Unmarshal(data, &j); e != nil {
+		return e
+	}
+	err.reset()
+	err.fromJSON(&j)
+	return nil
+}
+
+func (err *AError) toJSON() *jsonError {
+	j := &jsonError{
+		Code:     err.code,
+		Reason:   err.reason,
+		HTTPCode: err.httpCode,
+		Message:  err.message,
+		ID:       string(err.id),
+		Fields:   err.Fields(),
+		Stack:    err.StackTrace(),
+	}
+	if err.httpCode != 0 {
+		j.GRPCCode = err.grpcCode
+	}
+	for _, parent := range err.parents {
+		if p, ok := parent.(*AError); ok {
+			j.Parents = append(j.Parents, p.toJSON())
+			continue
+		}
+		j.Parents = append(j.Parents, &jsonError{Message: parent.Error()})
+	}
+	return j
+}
+
+func (err *AError) fromJSON(j *jsonError) {
+	err.withCode(j.Code).withReason(j.Reason)
+	err.httpCode, err.grpcCode = j.HTTPCode, j.GRPCCode
+	err.message = j.Message
+	err.id = append(err.id[:0], j.ID...)
+	err.frames = j.Stack
+	for key, value := range j.Fields {
+		err.setField(key, value)
+	}
+	for _, p := range j.Parents {
+		if p.Code == "" {
+			err.parents = append(err.parents, errors.New(p.Message))
+			continue
+		}
+		parent := &AError{}
+		parent.fromJSON(p)
+		err.parents = append(err.parents, parent)
+	}
+	err.render()
+}
@@ -0,0 +1,181 @@
+package aerrors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// WithMessageKey sets the key of the message in the catalog set with SetCatalog, and the
+// arguments the message template is formatted with.
+//
+// The message set with WithMessage stays the one of the error; the localized message is
+// sent next to it to the clients asking for a locale of the catalog.
+func (err *AError) WithMessageKey(key string, args ...any) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	err.messageKey = key
+	err.messageArgs = append(err.messageArgs[:0], args...)
+	return err
+}
+
+// MessageKey returns the key of the localized message of the error.
+func (err *AError) MessageKey() string {
+	if err == nil {
+		return ""
+	}
+	err.assertLive()
+	return err.messageKey
+}
+
+// Catalog holds message templates by locale.
+type Catalog interface {
+	// Lookup returns the message template of key in locale.
+	Lookup(locale, key string) (string, bool)
+}
+
+type catalogHolder struct {
+	c      Catalog
+	locale string
+}
+
+var catalog atomic.Pointer[catalogHolder]
+
+func init() {
+	catalog.Store(&catalogHolder{locale: "en"})
+}
+
+// SetCatalog sets the catalog messages are localized with; nil turns localization off.
+func SetCatalog(c Catalog) {
+	catalog.Store(&catalogHolder{c: c, locale: catalog.Load().locale})
+}
+
+// SetDefaultLocale sets the locale used when none of the locales asked for by the client
+// has the message, "en" by default.
+func SetDefaultLocale(locale string) {
+	catalog.Store(&catalogHolder{c: catalog.Load().c, locale: CanonicalLocale(locale)})
+}
+
+// Localize returns the message of the first error of the chain of err having a message
+// key, in the first of locales the catalog has it in, or else in the default locale.
+func Localize(err error, locales ...string) (locale, message string, ok bool) {
+	h := catalog.Load()
+	if h.c == nil {
+		return "", "", false
+	}
+	var e *AError
+	walk(err, func(err error) bool {
+		ae, ok := err.(*AError)
+		if ok && ae != nil && ae.messageKey != "" {
+			e = ae
+		}
+		return e != nil
+	})
+	if e == nil {
+		return "", "", false
+	}
+	for i := 0; i <= len(locales); i++ {
+		l := h.locale
+		if i < len(locales) {
+			l = locales[i]
+		}
+		for l = CanonicalLocale(l); l != ""; l = parentLocale(l) {
+			if tmpl, ok := h.c.Lookup(l, e.messageKey); ok {
+				if len(e.messageArgs) != 0 {
+					tmpl = fmt.Sprintf(tmpl, e.messageArgs...)
+				}
+				return l, tmpl, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// CanonicalLocale returns locale as a BCP 47 tag with the usual casing, such as "vi-VN" for
+// "vi_vn".
+func CanonicalLocale(locale string) string {
+	parts := strings.FieldsFunc(strings.TrimSpace(locale), func(r rune) bool { return r == '-' || r == '_' })
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p)
+		case len(p) == 4:
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// parentLocale returns locale without its last subtag, "" for a language.
+func parentLocale(locale string) string {
+	i := strings.LastIndexByte(locale, '-')
+	if i < 0 {
+		return ""
+	}
+	return locale[:i]
+}
+
+// AcceptLanguage returns the locales of an Accept-Language header by decreasing preference.
+func AcceptLanguage(header string) []string {
+	type pref struct {
+		locale string
+		q      float64
+	}
+	var prefs []pref
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f <= 0 {
+				continue
+			}
+			q = f
+		}
+		prefs = append(prefs, pref{CanonicalLocale(locale), q})
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+
+	locales := make([]string, len(prefs))
+	for i, p := range prefs {
+		locales[i] = p.locale
+	}
+	return locales
+}
+
+type localesKey struct{}
+
+// ContextWithLocales returns a copy of ctx carrying the locales asked for by the client.
+func ContextWithLocales(ctx context.Context, locales ...string) context.Context {
+	return context.WithValue(ctx, localesKey{}, locales)
+}
+
+// LocalesFromContext returns the locales carried by ctx or else the ones of the
+// accept-language header of the incoming gRPC metadata.
+func LocalesFromContext(ctx context.Context) []string {
+	if locales, ok := ctx.Value(localesKey{}).([]string); ok {
+		return locales
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	// grpc-gateway forwards the HTTP header with its prefix
+	for _, key := range []string{"accept-language", "grpcgateway-accept-language"} {
+		if values := md.Get(key); len(values) != 0 {
+			return AcceptLanguage(strings.Join(values, ","))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package aerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func testCatalog(t *testing.T) *MessageCatalog {
+	t.Helper()
+	c := NewMessageCatalog()
+	err := c.AddFS(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"user": {"not_found": "User %d was not found"}}`)},
+		"locales/vi.toml": {Data: []byte(`
+# Vietnamese
+[user]
+not_found = "Không tìm thấy người dùng %d" # inline comment
+"quoted.key" = 'literal \n'
+multiline = """
+first \
+second"""
+`)},
+		"locales/README.md": {Data: []byte("skipped")},
+	}, "locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCatalog(c)
+	t.Cleanup(func() { SetCatalog(nil) })
+	return c
+}
+
+func TestMessageCatalog(t *testing.T) {
+	c := testCatalog(t)
+
+	if got := c.Locales(); !reflect.DeepEqual(got, []string{"en", "vi"}) {
+		t.Errorf("Locales() = %v", got)
+	}
+	if got, _ := c.Lookup("vi", "user.quoted.key"); got != `literal \n` {
+		t.Errorf("Lookup() = %q", got)
+	}
+	if got, _ := c.Lookup("vi", "user.multiline"); got != "first second" {
+		t.Errorf("Lookup() = %q", got)
+	}
+	for _, doc := range []string{`key = 1`, `key "value"`, `[table`, `key = "unterminated`, `key = ["value"]`} {
+		if err := c.AddTOML("en", []byte(doc)); err == nil {
+			t.Errorf("AddTOML(%q) does not fail", doc)
+		}
+	}
+	for _, doc := range []string{`{"key": 1}`, `{"key": ["value"]}`, `{"key": "unterminated}`} {
+		if err := c.AddJSON("en", []byte(doc)); err == nil {
+			t.Errorf("AddJSON(%q) does not fail", doc)
+		}
+	}
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	got := AcceptLanguage("en-us;q=0.8, vi_VN, *;q=0.1, fr;q=0")
+	if want := []string{"vi-VN", "en-US"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AcceptLanguage() = %v, want %v", got, want)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "vi"))
+	if got := LocalesFromContext(ctx); !reflect.DeepEqual(got, []string{"vi"}) {
+		t.Errorf("LocalesFromContext() = %v", got)
+	}
+	if got := LocalesFromContext(ContextWithLocales(ctx, "en")); !reflect.DeepEqual(got, []string{"en"}) {
+		t.Errorf("LocalesFromContext() = %v", got)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	testCatalog(t)
+	err := Wrap(NotFound(fakeReason).WithMessage(fakeMessage).WithMessageKey("user.not_found", 42).Err(), "loading")
+
+	for _, tc := range []struct {
+		locales []string
+		locale  string
+		message string
+	}{
+		{[]string{"vi-VN"}, "vi", "Không tìm thấy người dùng 42"},
+		{[]string{"fr", "en-GB"}, "en", "User 42 was not found"},
+		{nil, "en", "User 42 was not found"},
+	} {
+		locale, message, ok := Localize(err, tc.locales...)
+		if !ok || locale != tc.locale || message != tc.message {
+			t.Errorf("Localize(%v) = %q, %q, %v", tc.locales, locale, message, ok)
+		}
+	}
+	if _, _, ok := Localize(NotFound(fakeReason).Err(), "en"); ok {
+		t.Error("Localize localizes an error without message key")
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "vi"))
+	s, _ := status.FromError(SendGRPCErrorContext(ctx, err))
+	var localized *errdetails.LocalizedMessage
+	for _, d := range s.Details() {
+		if d, ok := d.(*errdetails.LocalizedMessage); ok {
+			localized = d
+		}
+	}
+	if localized == nil || localized.Locale != "vi" || localized.Message != "Không tìm thấy người dùng 42" {
+		t.Errorf("LocalizedMessage = %v", localized)
+	}
+	if got := ReceiveGRPCError(s.Err()); TypeCode(got) != ErrNotFound.TypeCode() {
+		t.Errorf("received %s", got)
+	}
+}
+
+func TestWriteHTTPError(t *testing.T) {
+	testCatalog(t)
+	err := NotFound(fakeReason).WithMessage(fakeMessage).WithMessageKey("user.not_found", 42).WithField("tenant", "acme").Err()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "vi-VN,vi;q=0.9,en;q=0.8")
+	w := httptest.NewRecorder()
+	WriteHTTPError(w, r, err)
+
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Language") != "vi" {
+		t.Errorf("status %d, Content-Language %q", w.Code, w.Header().Get("Content-Language"))
+	}
+	var body HTTPErrorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := HTTPErrorBody{
+		Code:             ErrNotFound.TypeCode(),
+		Reason:           fakeReason,
+		Message:          fakeMessage,
+		ID:               ID(err),
+		LocalizedMessage: &LocalizedMessage{Locale: "vi", Message: "Không tìm thấy người dùng 42"},
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("body = %+v, want %+v", body, want)
+	}
+}
+
+func TestReadHTTPError(t *testing.T) {
+	testCatalog(t)
+	sent := Internal("saving order").
+		WithParent(NotFound(fakeReason).WithMessageKey("user.not_found", 42).WithField("tenant", "acme").Err()).
+		WithParent(errExample).
+		Err()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(ContextWithTrustedCaller(r.Context()))
+	w := httptest.NewRecorder()
+	WriteHTTPError(w, r, sent)
+
+	got := ReadHTTPError(w.Result())
+	if got.Error() != string(sent.(*AError).line()) {
+		t.Errorf("Error() = %q, want %q", got.Error(), string(sent.(*AError).line()))
+	}
+	if !IsInternal(got) || IsNotFound(got) || !errors.Is(got, ErrNotFound) || ID(got) != ID(sent) {
+		t.Errorf("received %v", got)
+	}
+	if Fields(got.(*AError).Causes()[0])["tenant"] != "acme" {
+		t.Errorf("parents not received: %v", got)
+	}
+
+	w = httptest.NewRecorder()
+	w.WriteHeader(http.StatusPaymentRequired)
+	_, _ = w.WriteString(`{"code": "PAYMENT_OVERDUE", "localized_message": {"locale": "vi", "message": "Quá hạn"}}`)
+	got = ReadHTTPError(w.Result())
+	data, _ := json.Marshal(Wrap(got, "paying order"))
+	decoded := &AError{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	grpcCode := statusToCode(http.StatusPaymentRequired).GRPCCode()
+	for _, err := range []error{got, Wrap(got, "paying order"), decoded} {
+		if TypeCode(err) != "PAYMENT_OVERDUE" || HTTPCode(err) != http.StatusPaymentRequired || GRPCCode(err) != grpcCode {
+			t.Errorf("unregistered code received as %s, %d, %s", TypeCode(err), HTTPCode(err), GRPCCode(err))
+		}
+	}
+	if d := got.(*AError).Details(); len(d) != 1 || d[0].(*errdetails.LocalizedMessage).Message != "Quá hạn" {
+		t.Errorf("Details() = %v", d)
+	}
+
+	w = httptest.NewRecorder()
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = w.WriteString("<html>upstream down</html>")
+	if got = ReadHTTPError(w.Result()); !IsUnavailable(got) || got.(*AError).Message() != "Service Unavailable" {
+		t.Errorf("received %v", got)
+	}
+
+	w = httptest.NewRecorder()
+	w.WriteHeader(http.StatusNoContent)
+	if got = ReadHTTPError(w.Result()); got != nil {
+		t.Errorf("received %v for a successful response", got)
+	}
+}
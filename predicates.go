@@ -0,0 +1,28 @@
+package aerrors
+
+// IsCode reports whether the first error in the chain of err carrying a code, found the way
+// errors.As finds it, has a code in the family of code. The codes of the errors it wraps are
+// ignored, so that an error wrapped with another code only matches the outer one.
+//
+// Errors without any code in their chain are considered to have the code the classifiers
+// map them onto, the same way TypeCode reports them.
+func IsCode(err error, code Code) bool {
+	if err == nil {
+		return false
+	}
+	return SameFamily(Code(TypeCode(err)), code)
+}
+
+// IsValidArgument reports whether err carries a code in the family of ErrInvalidArgument.
+//
+// Deprecated: Use IsInvalidArgument.
+func IsValidArgument(err error) bool {
+	return IsInvalidArgument(err)
+}
+
+// IsUnauthentication reports whether err carries a code in the family of ErrUnauthenticated.
+//
+// Deprecated: Use IsUnauthenticated.
+func IsUnauthentication(err error) bool {
+	return IsUnauthenticated(err)
+}
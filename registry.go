@@ -0,0 +1,209 @@
+package aerrors
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+)
+
+// CodeOption configures a code registered with Register.
+type CodeOption func(*codeInfo)
+
+// GRPCDefault makes the code the one a GRPC code is converted back to, such as when an
+// error is received without details. Only one code may be the default for a GRPC code.
+func GRPCDefault() CodeOption {
+	return func(info *codeInfo) {
+		info.grpcDefault = true
+	}
+}
+
+// InFamily makes the code a member of the family of canonical, an alias that the Is
+// predicates, SameFamily and AError.Is consider equal to canonical.
+//
+// canonical must already be registered, with the same GRPC code, and not be a member of
+// another family itself.
+func InFamily(canonical Code) CodeOption {
+	return func(info *codeInfo) {
+		info.family = canonical
+	}
+}
+
+type codeInfo struct {
+	code        Code
+	httpCode    int
+	grpcCode    codes.Code
+	grpcDefault bool
+	// family is the canonical code of the family, the code itself when not an alias
+	family Code
+}
+
+// codeRegistry is never modified once published; Register publishes a modified copy.
+type codeRegistry struct {
+	codes  map[Code]*codeInfo
+	grpc   map[codes.Code]Code
+	pinned map[codes.Code]bool
+	order  []Code
+}
+
+var (
+	registryMu sync.Mutex
+	// registry is set up before any init function runs so that codes can be registered from them
+	registry = func() *atomic.Pointer[codeRegistry] {
+		p := &atomic.Pointer[codeRegistry]{}
+		p.Store(&codeRegistry{
+			codes:  map[Code]*codeInfo{},
+			grpc:   map[codes.Code]Code{},
+			pinned: map[codes.Code]bool{},
+		})
+		return p
+	}()
+)
+
+// Register adds a code with the HTTP status and GRPC code it maps to.
+//
+// The first code registered for a GRPC code is the one that GRPC code converts back to,
+// unless another code is registered with the GRPCDefault option. Registering a code twice,
+// an invalid HTTP status, a second default code for a GRPC code or a family member that
+// does not fit its family is an error.
+func Register(code Code, httpStatus int, grpcCode codes.Code, opts ...CodeOption) error {
+	info := &codeInfo{code: code, httpCode: httpStatus, grpcCode: grpcCode, family: code}
+	for _, opt := range opts {
+		opt(info)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	cur := registry.Load()
+	switch {
+	case code == "":
+		return fmt.Errorf("aerrors: cannot register an empty code")
+	case cur.codes[code] != nil:
+		return fmt.Errorf("aerrors: code %s is already registered", code)
+	case httpStatus < 100 || httpStatus > 599:
+		return fmt.Errorf("aerrors: code %s has an invalid HTTP status %d", code, httpStatus)
+	case grpcCode > codes.Unauthenticated:
+		return fmt.Errorf("aerrors: code %s has an invalid GRPC code %d", code, grpcCode)
+	case info.grpcDefault && cur.pinned[grpcCode]:
+		return fmt.Errorf("aerrors: code %s conflicts with %s as the default code for %s",
+			code, cur.grpc[grpcCode], grpcCode)
+	}
+	if info.family != code {
+		canonical := cur.codes[info.family]
+		switch {
+		case canonical == nil:
+			return fmt.Errorf("aerrors: family %s of code %s is not registered", info.family, code)
+		case canonical.family != canonical.code:
+			return fmt.Errorf("aerrors: family %s of code %s is itself in the family %s",
+				info.family, code, canonical.family)
+		case canonical.grpcCode != grpcCode:
+			return fmt.Errorf("aerrors: code %s has the GRPC code %s, its family %s has %s",
+				code, grpcCode, info.family, canonical.grpcCode)
+		}
+	}
+
+	next := &codeRegistry{
+		codes:  make(map[Code]*codeInfo, len(cur.codes)+1),
+		grpc:   make(map[codes.Code]Code, len(cur.grpc)+1),
+		pinned: make(map[codes.Code]bool, len(cur.pinned)+1),
+		order:  append(cur.order[:len(cur.order):len(cur.order)], code),
+	}
+	for k, v := range cur.codes {
+		next.codes[k] = v
+	}
+	for k, v := range cur.grpc {
+		next.grpc[k] = v
+	}
+	for k, v := range cur.pinned {
+		next.pinned[k] = v
+	}
+	next.codes[code] = info
+	if _, ok := next.grpc[grpcCode]; !ok || info.grpcDefault {
+		next.grpc[grpcCode] = code
+	}
+	if info.grpcDefault {
+		next.pinned[grpcCode] = true
+	}
+	registry.Store(next)
+	return nil
+}
+
+// MustRegister is like Register but panics on error; use it to register codes at init time.
+func MustRegister(code Code, httpStatus int, grpcCode codes.Code, opts ...CodeOption) {
+	if err := Register(code, httpStatus, grpcCode, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// Codes returns every registered code in the order they were registered.
+func Codes() []Code {
+	order := registry.Load().order
+	return append([]Code(nil), order...)
+}
+
+// Registered reports whether the code has been registered.
+func (err Code) Registered() bool {
+	return registry.Load().codes[err] != nil
+}
+
+// Canonical returns the canonical code of the family of code, the one named after a GRPC
+// code for the built-in codes: Canonical(ErrConflict) is ErrAlreadyExists. Codes that are
+// not aliases, including unregistered codes, are their own canonical code.
+func Canonical(code Code) Code {
+	if info := lookupCode(code); info != nil {
+		return info.family
+	}
+	return code
+}
+
+// SameFamily reports whether both codes belong to the same family.
+func SameFamily(a, b Code) bool {
+	return a == b || Canonical(a) == Canonical(b)
+}
+
+// Family returns the canonical code of the family of code followed by its aliases in the
+// order they were registered.
+func Family(code Code) []Code {
+	reg := registry.Load()
+	canonical := Canonical(code)
+	family := []Code{canonical}
+	for _, c := range reg.order {
+		if c != canonical && reg.codes[c].family == canonical {
+			family = append(family, c)
+		}
+	}
+	return family
+}
+
+func lookupCode(code Code) *codeInfo {
+	return registry.Load().codes[code]
+}
+
+// HTTPCode returns the HTTP status registered for the code, or http.StatusInternalServerError
+// for unregistered codes.
+func (err Code) HTTPCode() int {
+	if info := lookupCode(err); info != nil {
+		return info.httpCode
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the GRPC code registered for the code, or codes.Internal for
+// unregistered codes.
+func (err Code) GRPCCode() codes.Code {
+	if info := lookupCode(err); info != nil {
+		return info.grpcCode
+	}
+	return codes.Internal
+}
+
+// convert a code to a known Error type;
+func codeToError(code codes.Code) Code {
+	if c, ok := registry.Load().grpc[code]; ok {
+		return c
+	}
+	return ErrInternal
+}
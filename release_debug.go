@@ -0,0 +1,6 @@
+//go:build aerrors_debug
+
+package aerrors
+
+// debugRelease poisons released AErrors so that any later use panics.
+const debugRelease = true
@@ -0,0 +1,29 @@
+//go:build aerrors_debug
+
+package aerrors
+
+import "testing"
+
+func TestUseAfterRelease(t *testing.T) {
+	err := Internal(fakeReason).Err()
+	Release(err)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on use after release")
+		}
+	}()
+	_ = err.Error()
+}
+
+func TestReleaseTwicePanics(t *testing.T) {
+	err := Internal(fakeReason).Err()
+	Release(err)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on second release")
+		}
+	}()
+	Release(err)
+}
@@ -0,0 +1,257 @@
+package aerrors
+
+import (
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain is the domain of the ErrorInfo details sent with the errors.
+var errorDomain atomic.Pointer[string]
+
+func init() {
+	SetErrorDomain("")
+}
+
+// SetErrorDomain sets the domain of the google.rpc.ErrorInfo details sent with the errors,
+// usually the DNS name of the service such as "orders.example.com". The domain must not be
+// empty: an empty domain sets the default one, the path of the main module of the program.
+func SetErrorDomain(domain string) {
+	if domain == "" {
+		domain = defaultErrorDomain()
+	}
+	errorDomain.Store(&domain)
+}
+
+func defaultErrorDomain() string {
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Path != "" {
+		return bi.Main.Path
+	}
+	return "aerrors"
+}
+
+// errorInfoReason returns reason as the UPPER_SNAKE_CASE reason of a google.rpc.ErrorInfo,
+// matching [A-Z][A-Z0-9_]+[A-Z0-9] and 63 characters at most, or "" when it has no letter
+// to start with.
+func errorInfoReason(reason string) string {
+	b := make([]byte, 0, len(reason))
+	for i := 0; i < len(reason) && len(b) < 63; i++ {
+		c := reason[i]
+		switch {
+		case 'a' <= c && c <= 'z':
+			c -= 'a' - 'A'
+		case 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9':
+			if len(b) == 0 {
+				continue
+			}
+		default:
+			if len(b) == 0 || b[len(b)-1] == '_' {
+				continue
+			}
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	for len(b) != 0 && b[len(b)-1] == '_' {
+		b = b[:len(b)-1]
+	}
+	if len(b) < 3 {
+		return ""
+	}
+	return string(b)
+}
+
+// errorInfoMetadata returns fields as the metadata of a google.rpc.ErrorInfo, whose keys
+// match [a-z][a-zA-Z0-9-_]+ and are 64 characters at most: a leading upper case letter is
+// lowered, other characters replaced by '_', and the keys left invalid dropped. The first of
+// the keys made equal, in sorted order, is kept.
+func errorInfoMetadata(fields map[string]string) map[string]string {
+	if len(fields) == 0 {
+		return nil
+	}
+	metadata := make(map[string]string, len(fields))
+	for _, key := range sortedKeys(fields) {
+		k := errorInfoKey(key)
+		if _, ok := metadata[k]; ok || k == "" {
+			continue
+		}
+		metadata[k] = fields[key]
+	}
+	return metadata
+}
+
+func errorInfoKey(key string) string {
+	b := make([]byte, 0, len(key))
+	for i := 0; i < len(key) && len(b) < 64; i++ {
+		c := key[i]
+		switch {
+		case len(b) == 0 && 'A' <= c && c <= 'Z':
+			c += 'a' - 'A'
+		case len(b) == 0 && !('a' <= c && c <= 'z'):
+			continue
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_':
+		default:
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	if len(b) < 2 {
+		return ""
+	}
+	return string(b)
+}
+
+// WithFieldViolation adds a field violation to the google.rpc.BadRequest detail of the error.
+func (err *AError) WithFieldViolation(field, description string) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	d := richDetail(err, func() *errdetails.BadRequest { return &errdetails.BadRequest{} })
+	d.FieldViolations = append(d.FieldViolations, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+	return err
+}
+
+// WithRetryDelay sets the google.rpc.RetryInfo detail of the error, telling clients how
+// long to wait before retrying.
+func (err *AError) WithRetryDelay(delay time.Duration) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	d := richDetail(err, func() *errdetails.RetryInfo { return &errdetails.RetryInfo{} })
+	d.RetryDelay = durationpb.New(delay)
+	return err
+}
+
+// WithQuotaViolation adds a violation to the google.rpc.QuotaFailure detail of the error.
+func (err *AError) WithQuotaViolation(subject, description string) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	d := richDetail(err, func() *errdetails.QuotaFailure { return &errdetails.QuotaFailure{} })
+	d.Violations = append(d.Violations, &errdetails.QuotaFailure_Violation{
+		Subject:     subject,
+		Description: description,
+	})
+	return err
+}
+
+// WithPreconditionViolation adds a violation to the google.rpc.PreconditionFailure detail
+// of the error.
+func (err *AError) WithPreconditionViolation(typ, subject, description string) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	d := richDetail(err, func() *errdetails.PreconditionFailure { return &errdetails.PreconditionFailure{} })
+	d.Violations = append(d.Violations, &errdetails.PreconditionFailure_Violation{
+		Type:        typ,
+		Subject:     subject,
+		Description: description,
+	})
+	return err
+}
+
+// WithResourceInfo adds a google.rpc.ResourceInfo detail describing the resource the
+// error is about.
+func (err *AError) WithResourceInfo(resourceType, resourceName, owner, description string) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	err.details = append(err.details, &errdetails.ResourceInfo{
+		ResourceType: resourceType,
+		ResourceName: resourceName,
+		Owner:        owner,
+		Description:  description,
+	})
+	return err
+}
+
+// WithHelpLink adds a link to the google.rpc.Help detail of the error.
+func (err *AError) WithHelpLink(description, url string) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	d := richDetail(err, func() *errdetails.Help { return &errdetails.Help{} })
+	d.Links = append(d.Links, &errdetails.Help_Link{Description: description, Url: url})
+	return err
+}
+
+// WithDebugInfo sets the detail of the google.rpc.DebugInfo of the error, sent along with
+// its stack when DetailDebug is selected.
+func (err *AError) WithDebugInfo(detail string) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	d := richDetail(err, func() *errdetails.DebugInfo { return &errdetails.DebugInfo{} })
+	d.Detail = detail
+	return err
+}
+
+// Details returns the google.rpc details of the error, such as *errdetails.BadRequest,
+// added by the builder or received along with it.
+func (err *AError) Details() []proto.Message {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	return append([]proto.Message(nil), err.details...)
+}
+
+// richDetail returns the detail of type T of err, adding the one returned by newDetail when
+// there is none.
+func richDetail[T proto.Message](err *AError, newDetail func() T) T {
+	for _, d := range err.details {
+		if t, ok := d.(T); ok {
+			return t
+		}
+	}
+	t := newDetail()
+	err.details = append(err.details, t)
+	return t
+}
+
+// debugInfo returns the DebugInfo of err with the entries of its stack, nil when it has
+// neither.
+func (err *AError) debugInfo() *errdetails.DebugInfo {
+	var detail string
+	for _, d := range err.details {
+		if d, ok := d.(*errdetails.DebugInfo); ok {
+			detail = d.Detail
+		}
+	}
+	frames := err.StackTrace()
+	if detail == "" && len(frames) == 0 {
+		return nil
+	}
+	info := &errdetails.DebugInfo{Detail: detail, StackEntries: make([]string, len(frames))}
+	for i, f := range frames {
+		info.StackEntries[i] = f.File + ":" + strconv.Itoa(f.Line) + " " + f.Function
+	}
+	return info
+}
+
+// isRichDetail reports whether d is one of the google.rpc details an AError carries.
+func isRichDetail(d any) bool {
+	switch d.(type) {
+	case *errdetails.BadRequest, *errdetails.RetryInfo, *errdetails.QuotaFailure,
+		*errdetails.PreconditionFailure, *errdetails.ResourceInfo, *errdetails.Help,
+		*errdetails.DebugInfo, *errdetails.LocalizedMessage, *errdetails.RequestInfo:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package aerrors
+
+import (
+	"google.golang.org/grpc/codes"
+)
+
+type sentinel struct {
+	code   Code
+	reason string
+}
+
+// Sentinel returns an error that errors.Is matches with any error carrying a code in the
+// family of code together with reason, so that callers do not have to build throwaway
+// errors to compare with:
+//
+//	var ErrUserNotFound = aerrors.Sentinel(aerrors.ErrNotFound, "user not found")
+//
+//	if errors.Is(err, ErrUserNotFound) {
+//		...
+//	}
+func Sentinel(code Code, reason string) error {
+	return &sentinel{code: code, reason: reason}
+}
+
+func (s *sentinel) Error() string {
+	return "code:" + s.code.Error() + ",reason:" + s.reason
+}
+
+func (s *sentinel) TypeCode() string {
+	return s.code.TypeCode()
+}
+
+func (s *sentinel) HTTPCode() int {
+	return s.code.HTTPCode()
+}
+
+func (s *sentinel) GRPCCode() codes.Code {
+	return s.code.GRPCCode()
+}
+
+// matchCode reports whether an error with the given code, reason and definition is matched
+// by target, when target is a Code, a Sentinel or a Definition.
+//
+// Errors without definition, such as received ones, match a Definition on code and reason.
+func matchCode(code Code, reason string, def *Definition, target error) (matched, ok bool) {
+	switch t := target.(type) {
+	case Code:
+		return SameFamily(code, t), true
+	case *sentinel:
+		return SameFamily(code, t.code) && reason == t.reason, true
+	case *Definition:
+		if def != nil {
+			return def == t, true
+		}
+		return SameFamily(code, t.code) && reason == t.reason, true
+	}
+	return false, false
+}
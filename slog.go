@@ -0,0 +1,160 @@
+package aerrors
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+)
+
+// LogValue implements slog.LogValuer, logging the error as a group.
+//
+// The stack is left out; wrap the handler with NewSlogHandler to log it as a separate attribute.
+func (err *AError) LogValue() slog.Value {
+	if err == nil {
+		return slog.StringValue("<nil>")
+	}
+	err.assertLive()
+	attrs := make([]slog.Attr, 0, 8)
+	attrs = append(attrs,
+		slog.String("code", err.code.TypeCode()),
+		slog.String("reason", err.reason),
+	)
+	if err.message != "" {
+		attrs = append(attrs, slog.String("message", err.message))
+	}
+	attrs = append(attrs,
+		slog.Int("http", err.HTTPCode()),
+		slog.String("grpc", err.GRPCCode().String()),
+	)
+	if len(err.id) != 0 {
+		attrs = append(attrs, slog.String("id", string(err.id)))
+	}
+	if len(err.parents) != 0 {
+		attrs = append(attrs, slog.String("parent", err.parentLine()))
+	}
+	if len(err.fields) != 0 {
+		fields := make([]any, 0, len(err.fields))
+		for _, f := range err.fields {
+			fields = append(fields, slog.Any(f.key, f.value))
+		}
+		attrs = append(attrs, slog.Group("fields", fields...))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// parentLine returns the parents of the error separated by "; ", rendering the AError ones
+// without their stack as in the line of the error.
+func (err *AError) parentLine() string {
+	b := make([]byte, 0, 64)
+	for i, parent := range err.parents {
+		if i > 0 {
+			b = append(b, "; "...)
+		}
+		if p, ok := parent.(*AError); ok {
+			b = append(b, p.line()...)
+			continue
+		}
+		b = append(b, parent.Error()...)
+	}
+	return BytesToString(b)
+}
+
+// SlogOptions are options for NewSlogHandler.
+type SlogOptions struct {
+	// Stack adds the stack of an AError as a separate "<key>_stack" attribute.
+	Stack bool
+}
+
+type slogHandler struct {
+	next slog.Handler
+	opts SlogOptions
+}
+
+// NewSlogHandler returns a slog.Handler expanding every error-valued attribute before
+// passing the record to next.
+//
+// Errors are logged as groups holding their code, reason, message, HTTP and GRPC codes,
+// ID and fields, including errors that only wrap an AError. A nil opts is the same as
+// the zero value.
+func NewSlogHandler(next slog.Handler, opts *SlogOptions) slog.Handler {
+	h := &slogHandler{next: next}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.expand(nil, a)...)
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		expanded = h.expand(expanded, a)
+	}
+	return &slogHandler{next: h.next.WithAttrs(expanded), opts: h.opts}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	return &slogHandler{next: h.next.WithGroup(name), opts: h.opts}
+}
+
+// expand appends a to dst with every error replaced by its group, walking nested groups.
+func (h *slogHandler) expand(dst []slog.Attr, a slog.Attr) []slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			attrs = h.expand(attrs, ga)
+		}
+		return append(dst, slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)})
+	case slog.KindAny, slog.KindLogValuer:
+		err, ok := a.Value.Any().(error)
+		if !ok || err == nil {
+			break
+		}
+		dst = append(dst, slog.Attr{Key: a.Key, Value: errorLogValue(err)})
+		if h.opts.Stack {
+			if e := stackOf(err); e != nil && e.Stack() != "" {
+				dst = append(dst, slog.String(a.Key+"_stack", e.Stack()))
+			}
+		}
+		return dst
+	}
+	return append(dst, a)
+}
+
+// errorLogValue returns the group logged for err.
+func errorLogValue(err error) slog.Value {
+	var ae *AError
+	if errors.As(err, &ae) {
+		return ae.LogValue()
+	}
+	return slog.GroupValue(
+		slog.String("code", TypeCode(err)),
+		slog.String("message", err.Error()),
+		slog.Int("http", HTTPCode(err)),
+		slog.String("grpc", GRPCCode(err).String()),
+	)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
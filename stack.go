@@ -1,28 +1,412 @@
 package aerrors
 
 import (
+	"path"
 	"runtime"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+// Frame is a symbolized frame of a stack trace.
+type Frame struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// frameCache holds the frames symbolized for every program counter seen so far.
+var frameCache sync.Map // map[uintptr][]Frame
+
 // LogStack return call function stack info from start stack to end stack.
 // if end is a positive number, return all call function stack.
 func LogStack(start, end int) string {
-	var sb strings.Builder
-	buf := make([]byte, 0, 32)
-	for i := start; i < end || end <= 0; i++ {
-		pc, str, line, _ := runtime.Caller(i)
-		if line == 0 {
+	depth := end - start
+	if end <= 0 {
+		depth = 256
+	}
+	pcs := callers(nil, start, depth)
+	return BytesToString(appendFrames(nil, loadStackConfig().frames(symbolize(nil, pcs))))
+}
+
+// callers records at most depth program counters into dst. A skip of 0 starts at
+// the caller of callers, 1 at its caller and so on.
+func callers(dst []uintptr, skip, depth int) []uintptr {
+	if depth <= 0 {
+		return dst[:0]
+	}
+	if cap(dst) < depth {
+		dst = make([]uintptr, depth)
+	}
+	dst = dst[:depth]
+	return dst[:runtime.Callers(skip+2, dst)]
+}
+
+// symbolize appends the frames of pcs to dst, using frameCache to only resolve
+// every program counter once.
+func symbolize(dst []Frame, pcs []uintptr) []Frame {
+	for _, pc := range pcs {
+		if frames, ok := frameCache.Load(pc); ok {
+			dst = append(dst, frames.([]Frame)...)
+			continue
+		}
+		var frames []Frame
+		it := runtime.CallersFrames([]uintptr{pc})
+		for {
+			f, more := it.Next()
+			if f.Line != 0 {
+				frames = append(frames, Frame{Function: f.Function, File: f.File, Line: f.Line})
+			}
+			if !more {
+				break
+			}
+		}
+		frameCache.Store(pc, frames)
+		dst = append(dst, frames...)
+	}
+	return dst
+}
+
+// appendFrames appends the frames to dst as "file:line\tfunction" lines.
+func appendFrames(dst []byte, frames []Frame) []byte {
+	for _, f := range frames {
+		dst = append(dst, f.File...)
+		dst = append(dst, ':')
+		dst = strconv.AppendInt(dst, int64(f.Line), 10)
+		dst = append(dst, '\t')
+		dst = append(dst, f.Function...)
+		dst = append(dst, '\n')
+	}
+	return dst
+}
+
+// StackTrace returns the frames of the stack captured for the error, or inherited
+// from its causes, after filtering and path trimming.
+//
+// Frames are only symbolized when first asked for.
+func (err *AError) StackTrace() []Frame {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	cfg := err.stackCfg
+	if cfg == nil {
+		cfg = loadStackConfig()
+	}
+	if len(err.pcs) != 0 {
+		return cfg.frames(symbolize(nil, err.pcs))
+	}
+	if len(err.frames) == 0 {
+		return nil
+	}
+	return cfg.frames(append([]Frame(nil), err.frames...))
+}
+
+// Stack returns the stack of the error formatted as "file:line\tfunction" lines.
+func (err *AError) Stack() string {
+	if err == nil {
+		return ""
+	}
+	err.assertLive()
+	if !err.hasStack() {
+		return ""
+	}
+	return BytesToString(err.appendStack(nil))
+}
+
+func (err *AError) hasStack() bool {
+	return len(err.pcs) != 0 || len(err.frames) != 0
+}
+
+func (err *AError) appendStack(dst []byte) []byte {
+	return appendFrames(dst, err.StackTrace())
+}
+
+// captureStack records the stack of the caller skip frames above the caller of captureStack,
+// following the active StackPolicy.
+func (err *AError) captureStack(skip int, opts []StackOption) {
+	mode := err.stackMode()
+	if mode == StackNever {
+		return
+	}
+	cfg := loadStackConfig()
+	if len(opts) != 0 {
+		c := *cfg
+		for _, opt := range opts {
+			opt(&c)
+		}
+		cfg = &c
+	}
+	err.stackCfg = cfg
+	err.frames = nil
+	if mode != StackCallerOnly {
+		err.pcs = callers(err.pcs, skip+1+cfg.skip, cfg.depth)
+		return
+	}
+	// the caller is the first frame that is not in a helper, which frames would drop
+	for i := 0; i < cfg.depth; i++ {
+		err.pcs = callers(err.pcs, skip+1+cfg.skip+i, 1)
+		if len(err.pcs) == 0 || !isHelperPC(err.pcs[0]) {
+			return
+		}
+	}
+}
+
+// isHelperPC reports whether every function of the frames of pc, inlined ones included, is
+// a helper.
+func isHelperPC(pc uintptr) bool {
+	var buf [4]Frame
+	for _, f := range symbolize(buf[:0], []uintptr{pc}) {
+		if !isHelper(f.Function) {
+			return false
+		}
+	}
+	return true
+}
+
+// inheritStack makes err share the stack of from.
+func (err *AError) inheritStack(from *AError) {
+	err.pcs = append(err.pcs[:0], from.pcs...)
+	err.frames = from.frames
+	err.stackCfg = from.stackCfg
+}
+
+// stackOf returns the first error in the chain of err that carries a stack, or nil.
+func stackOf(err error) *AError {
+	var found *AError
+	walk(err, func(err error) bool {
+		e, ok := err.(*AError)
+		if ok && e != nil && e.hasStack() {
+			found = e
+		}
+		return found != nil
+	})
+	return found
+}
+
+// helpers holds the names of the functions marked as helpers.
+var helpers sync.Map // map[string]struct{}
+
+// helperPCs holds the callers of Helper seen so far, sparing the symbolization on later calls.
+var helperPCs sync.Map // map[uintptr]struct{}
+
+// Helper marks the calling function as an error helper, the same way testing.T.Helper
+// marks test helpers.
+//
+// Stacks leave out the frames of helpers found at their top, so that an error built by
+// a shared helper points at the caller of the helper:
+//
+//	func dbErr(err error) error {
+//		aerrors.Helper()
+//		return aerrors.Internal("database failure").WithParent(err).WithStack().Err()
+//	}
+func Helper() {
+	var pc [1]uintptr
+	if runtime.Callers(2, pc[:]) == 0 {
+		return
+	}
+	if _, ok := helperPCs.Load(pc[0]); ok {
+		return
+	}
+	f, _ := runtime.CallersFrames(pc[:]).Next()
+	helpers.Store(f.Function, struct{}{})
+	helperPCs.Store(pc[0], struct{}{})
+}
+
+// RegisterHelpers marks functions as error helpers by their fully qualified names, such as
+// "github.com/org/svc/repo.(*Repo).dbErr", for helpers that cannot call Helper themselves.
+func RegisterHelpers(functions ...string) {
+	for _, function := range functions {
+		helpers.Store(function, struct{}{})
+	}
+}
+
+func isHelper(function string) bool {
+	_, ok := helpers.Load(function)
+	return ok
+}
+
+// StackOption configures how stacks are captured and printed.
+type StackOption func(*stackConfig)
+
+// FrameFilter reports whether a frame must be dropped from stack traces.
+type FrameFilter func(Frame) bool
+
+type stackConfig struct {
+	depth        int
+	skip         int
+	filters      []FrameFilter
+	trimPaths    bool
+	trimPrefixes []string
+}
+
+var defaultStackConfig = stackConfig{
+	depth:     32,
+	trimPaths: true,
+}
+
+var globalStackConfig atomic.Pointer[stackConfig]
+
+func init() {
+	SetStackOptions()
+}
+
+func loadStackConfig() *stackConfig {
+	return globalStackConfig.Load()
+}
+
+// SetStackOptions sets the options used by WithStack, replacing the ones set before.
+//
+// By default up to 32 frames are captured starting at the caller of WithStack, no
+// frame is filtered and paths are trimmed.
+func SetStackOptions(opts ...StackOption) {
+	cfg := defaultStackConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	globalStackConfig.Store(&cfg)
+}
+
+// StackDepth sets the maximum number of frames captured.
+func StackDepth(depth int) StackOption {
+	return func(cfg *stackConfig) {
+		cfg.depth = depth
+	}
+}
+
+// StackSkip sets the number of frames skipped above the caller of WithStack.
+func StackSkip(skip int) StackOption {
+	return func(cfg *stackConfig) {
+		cfg.skip = skip
+	}
+}
+
+// StackFilters adds filters dropping frames from stack traces.
+func StackFilters(filters ...FrameFilter) StackOption {
+	return func(cfg *stackConfig) {
+		cfg.filters = append(cfg.filters[:len(cfg.filters):len(cfg.filters)], filters...)
+	}
+}
+
+// StackTrimPaths turns path trimming on or off.
+//
+// Trimmed paths are made of the import path of the package followed by the file name,
+// such as "github.com/org/svc/pkg/file.go", whatever machine built the binary. The files of
+// package main are named after the import path of the program recorded in the binary.
+func StackTrimPaths(enabled bool) StackOption {
+	return func(cfg *stackConfig) {
+		cfg.trimPaths = enabled
+	}
+}
+
+// StackTrimPrefixes adds prefixes removed from file paths before any other trimming.
+func StackTrimPrefixes(prefixes ...string) StackOption {
+	return func(cfg *stackConfig) {
+		cfg.trimPrefixes = append(cfg.trimPrefixes[:len(cfg.trimPrefixes):len(cfg.trimPrefixes)], prefixes...)
+	}
+}
+
+// Filters for the frames of the Go runtime, the testing package and the net/http server.
+var (
+	RuntimeFrames FrameFilter = packageFrames("runtime")
+	TestingFrames FrameFilter = packageFrames("testing")
+	NetHTTPFrames FrameFilter = packageFrames("net/http")
+)
+
+func packageFrames(pkg string) FrameFilter {
+	return func(f Frame) bool {
+		return funcPackage(f.Function) == pkg
+	}
+}
+
+// frames drops the leading helper frames, then filters and trims the rest in place.
+func (cfg *stackConfig) frames(frames []Frame) []Frame {
+	for len(frames) != 0 && isHelper(frames[0].Function) {
+		frames = frames[1:]
+	}
+	out := frames[:0]
+	for _, f := range frames {
+		if cfg.drop(f) {
+			continue
+		}
+		f.File = cfg.trim(f)
+		out = append(out, f)
+	}
+	return out
+}
+
+func (cfg *stackConfig) drop(f Frame) bool {
+	for _, filter := range cfg.filters {
+		if filter(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg *stackConfig) trim(f Frame) string {
+	for _, prefix := range cfg.trimPrefixes {
+		if strings.HasPrefix(f.File, prefix) {
+			return strings.TrimPrefix(f.File[len(prefix):], "/")
+		}
+	}
+	if !cfg.trimPaths {
+		return f.File
+	}
+	pkg := strings.TrimSuffix(funcPackage(f.Function), "_test")
+	if pkg == "" {
+		return f.File
+	}
+	// the files of package main are named after the import path of the program
+	if pkg == "main" {
+		pkg = mainPackage()
+	}
+	return pkg + "/" + path.Base(f.File)
+}
+
+// mainPackage returns the import path of package main, "main" when the binary does not
+// record it.
+var mainPackage = sync.OnceValue(func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Path != "" && bi.Path != "command-line-arguments" {
+		return strings.TrimSuffix(bi.Path, ".test")
+	}
+	return "main"
+})
+
+// funcPackage returns the import path of the package of a fully qualified function name
+// such as "github.com/org/svc/pkg.(*T).Method".
+//
+// The linker escapes the dots of the last element of import paths, as in
+// "gopkg.in/yaml%2ev3.(*decoder).unmarshal"; unescaped names are read the same, taking
+// major version suffixes such as ".v3" as part of the path.
+func funcPackage(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	name := function[slash+1:]
+	dot := strings.IndexByte(name, '.')
+	if dot < 0 {
+		return ""
+	}
+	for !strings.Contains(name[:dot], "%2e") {
+		next := strings.IndexByte(name[dot+1:], '.')
+		if next < 0 || !isMajorVersion(name[dot+1:dot+1+next]) {
 			break
 		}
-		x := strconv.AppendInt(buf, int64(line), 10)
-		sb.Write(StringToBytes(str))
-		sb.Write(StringToBytes(":"))
-		sb.Write(x)
-		sb.Write(StringToBytes("\t"))
-		sb.Write(StringToBytes(runtime.FuncForPC(pc).Name()))
-		sb.Write(StringToBytes("\n"))
+		dot += 1 + next
+	}
+	return strings.ReplaceAll(function[:slash+1+dot], "%2e", ".")
+}
+
+// isMajorVersion reports whether s is a major version such as "v3".
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
 	}
-	return sb.String()
+	return true
 }
@@ -0,0 +1,124 @@
+package aerrors
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// StackMode is the way WithStack captures a stack.
+type StackMode int
+
+const (
+	// StackAlways captures the whole stack.
+	StackAlways StackMode = iota
+	// StackNever captures nothing; WithStack is a no-op.
+	StackNever
+	// StackCallerOnly captures a single frame: the caller of WithStack.
+	StackCallerOnly
+)
+
+// StackMode implements StackPolicy, so that a StackMode can be used as a policy applying
+// the same mode to every code.
+func (mode StackMode) StackMode(Code) StackMode {
+	return mode
+}
+
+// StackPolicy decides how WithStack captures the stack of an error with the given code.
+type StackPolicy interface {
+	StackMode(code Code) StackMode
+}
+
+// StackPolicyFunc is an adapter to use an ordinary function as a StackPolicy.
+type StackPolicyFunc func(code Code) StackMode
+
+// StackMode calls f(code).
+func (f StackPolicyFunc) StackMode(code Code) StackMode {
+	return f(code)
+}
+
+// StackByCode returns a policy using the mode given for the code of the error, or
+// otherwise for codes missing from modes.
+//
+//	aerrors.StackByCode(map[aerrors.Code]aerrors.StackMode{
+//		aerrors.ErrInternal: aerrors.StackAlways,
+//		aerrors.ErrDataLoss: aerrors.StackAlways,
+//	}, aerrors.StackNever)
+func StackByCode(modes map[Code]StackMode, otherwise StackMode) StackPolicy {
+	m := make(map[Code]StackMode, len(modes))
+	for code, mode := range modes {
+		m[code] = mode
+	}
+	return StackPolicyFunc(func(code Code) StackMode {
+		if mode, ok := m[code]; ok {
+			return mode
+		}
+		return otherwise
+	})
+}
+
+// StackSampled returns a policy deferring to policy for a rate, between 0 and 1, of the
+// errors and capturing nothing for the others.
+func StackSampled(rate float64, policy StackPolicy) StackPolicy {
+	return StackPolicyFunc(func(code Code) StackMode {
+		if rand.Float64() >= rate {
+			return StackNever
+		}
+		return policy.StackMode(code)
+	})
+}
+
+type stackPolicyHolder struct {
+	p StackPolicy
+}
+
+var globalStackPolicy atomic.Pointer[stackPolicyHolder]
+
+func init() {
+	SetStackPolicy(StackAlways)
+}
+
+// SetStackPolicy sets the policy followed by WithStack when the builder has no policy
+// from its context. StackAlways is used by default.
+func SetStackPolicy(policy StackPolicy) {
+	if policy == nil {
+		policy = StackAlways
+	}
+	globalStackPolicy.Store(&stackPolicyHolder{p: policy})
+}
+
+type stackPolicyKey struct{}
+
+// ContextWithStackPolicy returns a copy of ctx carrying policy, followed by WithStack for
+// builders given ctx through WithContext.
+func ContextWithStackPolicy(ctx context.Context, policy StackPolicy) context.Context {
+	return context.WithValue(ctx, stackPolicyKey{}, policy)
+}
+
+// StackPolicyFromContext returns the policy carried by ctx, or nil.
+func StackPolicyFromContext(ctx context.Context) StackPolicy {
+	policy, _ := ctx.Value(stackPolicyKey{}).(StackPolicy)
+	return policy
+}
+
+// WithContext applies what ctx carries to the builder, such as a StackPolicy.
+//
+// Call it before WithStack.
+func (err *AError) WithContext(ctx context.Context) Builder {
+	if err == nil {
+		return nil
+	}
+	err.assertLive()
+	if ctx != nil {
+		err.policy = StackPolicyFromContext(ctx)
+	}
+	return err
+}
+
+// stackMode returns the mode the active policy applies to err.
+func (err *AError) stackMode() StackMode {
+	if err.policy != nil {
+		return err.policy.StackMode(err.code)
+	}
+	return globalStackPolicy.Load().p.StackMode(err.code)
+}
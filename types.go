@@ -1,6 +1,17 @@
 package aerrors
 
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+//go:generate go run ./internal/cmd/gencodes -in types.go -out codes_gen.go
+
 // Errors named in line with GRPC codes and some that overlap with HTTP statuses
+//
+// A constructor, a formatted constructor and a predicate are generated for every code
+// declared in this file; run go generate after adding one.
 const (
 	ErrOK                 Code = "OK"                  // HTTP: 200 GRPC: codes.OK
 	ErrCanceled           Code = "CANCELED"            // HTTP: 408 GRPC: codes.Canceled
@@ -40,83 +51,47 @@ const (
 	ErrGatewayTimeout             Code = "GATEWAY_TIMEOUT"               // HTTP: 504 GRPC: codes.DeadlineExceeded
 )
 
-func InvalidArgument(reason string) Builder {
-	return New(ErrInvalidArgument, reason)
-}
-
-func IsValidArgument(err *AError) bool {
-	return err.code == ErrInvalidArgument
-}
-
-func FailedPrecondition(reason string) Builder {
-	return New(ErrFailedPrecondition, reason)
-}
-
-func IsFailedPrecondition(err *AError) bool {
-	return err.code == ErrFailedPrecondition
-}
-
+func init() {
+	// GRPC Errors; the codes GRPC codes convert back to
+	MustRegister(ErrOK, http.StatusOK, codes.OK, GRPCDefault())
+	MustRegister(ErrCanceled, http.StatusRequestTimeout, codes.Canceled, GRPCDefault())
+	MustRegister(ErrUnknown, http.StatusNotExtended, codes.Unknown, GRPCDefault())
+	MustRegister(ErrInvalidArgument, http.StatusBadRequest, codes.InvalidArgument, GRPCDefault())
+	MustRegister(ErrDeadlineExceeded, http.StatusGatewayTimeout, codes.DeadlineExceeded, GRPCDefault())
+	MustRegister(ErrNotFound, http.StatusNotFound, codes.NotFound, GRPCDefault())
+	MustRegister(ErrAlreadyExists, http.StatusConflict, codes.AlreadyExists, GRPCDefault())
+	MustRegister(ErrPermissionDenied, http.StatusForbidden, codes.PermissionDenied, GRPCDefault())
+	MustRegister(ErrResourceExhausted, http.StatusTooManyRequests, codes.ResourceExhausted, GRPCDefault())
+	MustRegister(ErrFailedPrecondition, http.StatusBadRequest, codes.FailedPrecondition, GRPCDefault())
+	MustRegister(ErrAborted, http.StatusConflict, codes.Aborted, GRPCDefault())
+	MustRegister(ErrOutOfRange, http.StatusUnprocessableEntity, codes.OutOfRange, GRPCDefault())
+	MustRegister(ErrUnimplemented, http.StatusNotImplemented, codes.Unimplemented, GRPCDefault())
+	MustRegister(ErrInternal, http.StatusInternalServerError, codes.Internal, GRPCDefault())
+	MustRegister(ErrUnavailable, http.StatusServiceUnavailable, codes.Unavailable, GRPCDefault())
+	MustRegister(ErrDataLoss, http.StatusInternalServerError, codes.DataLoss, GRPCDefault())
+	MustRegister(ErrUnauthenticated, http.StatusUnauthorized, codes.Unauthenticated, GRPCDefault())
+
+	// HTTP Errors; aliases of the GRPC errors they overlap with
+	MustRegister(ErrBadRequest, http.StatusBadRequest, codes.InvalidArgument, InFamily(ErrInvalidArgument))
+	MustRegister(ErrUnauthorized, http.StatusUnauthorized, codes.Unauthenticated, InFamily(ErrUnauthenticated))
+	MustRegister(ErrForbidden, http.StatusForbidden, codes.PermissionDenied, InFamily(ErrPermissionDenied))
+	MustRegister(ErrMethodNotAllowed, http.StatusMethodNotAllowed, codes.Unimplemented, InFamily(ErrUnimplemented))
+	MustRegister(ErrRequestTimeout, http.StatusRequestTimeout, codes.DeadlineExceeded, InFamily(ErrDeadlineExceeded))
+	MustRegister(ErrConflict, http.StatusConflict, codes.AlreadyExists, InFamily(ErrAlreadyExists))
+	MustRegister(ErrImATeapot, http.StatusTeapot, codes.Unknown)
+	MustRegister(ErrUnprocessableEntity, http.StatusUnprocessableEntity, codes.InvalidArgument, InFamily(ErrInvalidArgument))
+	MustRegister(ErrTooManyRequests, http.StatusTooManyRequests, codes.ResourceExhausted, InFamily(ErrResourceExhausted))
+	MustRegister(ErrUnavailableForLegalReasons, http.StatusUnavailableForLegalReasons, codes.Unavailable)
+	MustRegister(ErrInternalServerError, http.StatusInternalServerError, codes.Internal, InFamily(ErrInternal))
+	MustRegister(ErrNotImplemented, http.StatusNotImplemented, codes.Unimplemented, InFamily(ErrUnimplemented))
+	MustRegister(ErrBadGateway, http.StatusBadGateway, codes.Aborted)
+	MustRegister(ErrServiceUnavailable, http.StatusServiceUnavailable, codes.Unavailable, InFamily(ErrUnavailable))
+	MustRegister(ErrGatewayTimeout, http.StatusGatewayTimeout, codes.DeadlineExceeded, InFamily(ErrDeadlineExceeded))
+}
+
+// Unauthentication returns a Builder for an error with the code ErrUnauthenticated.
+//
+// Deprecated: Use Unauthenticated.
 func Unauthentication(reason string) Builder {
-	return New(ErrUnauthenticated, reason)
-}
-
-func IsUnauthentication(err *AError) bool {
-	return err.code == ErrUnauthenticated || err.code == ErrUnauthorized
-}
-
-func PermissionDenied(reason string) Builder {
-	return New(ErrPermissionDenied, reason)
-}
-
-func IsPermissionDenied(err *AError) bool {
-	return err.code == ErrPermissionDenied || err.code == ErrForbidden
-}
-
-func NotFound(reason string) Builder {
-	return New(ErrNotFound, reason)
-}
-
-func IsNotFound(err *AError) bool {
-	return err.code == ErrNotFound
-}
-
-func AlreadyExists(reason string) Builder {
-	return New(ErrConflict, reason)
-}
-
-func IsAlreadyExists(err *AError) bool {
-	return err.code == ErrAlreadyExists || err.code == ErrConflict || err.code == ErrAborted
-}
-
-func Internal(reason string) Builder {
-	return New(ErrInternal, reason)
-}
-
-func IsInternal(err *AError) bool {
-	return err.code == ErrInternal || err.code == ErrInternalServerError || err.code == ErrDataLoss
-}
-
-func Unimplemented(reason string) Builder {
-	return New(ErrUnimplemented, reason)
-}
-
-func IsUnimplemented(err *AError) bool {
-	return err.code == ErrUnimplemented || err.code == ErrMethodNotAllowed ||
-		err.code == ErrNotImplemented
-}
-
-func Unavailable(reason string) Builder {
-	return New(ErrUnavailable, reason)
-}
-
-func IsUnavailable(err *AError) bool {
-	return err.code == ErrUnavailable || err.code == ErrServiceUnavailable
-}
-
-func DeadlineExceeded(reason string) Builder {
-	return New(ErrGatewayTimeout, reason)
-}
-
-func IsDeadlineExceeded(err *AError) bool {
-	return err.code == ErrDeadlineExceeded || err.code == ErrGatewayTimeout
+	return Unauthenticated(reason)
 }
@@ -0,0 +1,100 @@
+package aerrors
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Wrap returns an error with err as its parent and message as its message.
+//
+// When err is, or wraps, an *AError or a Code the result keeps that code, the reason,
+// the ID, the fields, the message key, the google.rpc details and the stack, and message is
+// used unaltered. Any other error results in an ErrUnknown error with the message formatted
+// as "<message>: <error>".
+//
+// A stack is captured only when no error in the chain of err carries one.
+// If err is nil then Wrap returns nil.
+func Wrap(err error, message string) Error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, "", "", message, false)
+}
+
+// Wrapf is Wrap with the message formatted according to a format specifier.
+func Wrapf(err error, format string, args ...any) Error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, "", "", fmt.Sprintf(format, args...), false)
+}
+
+// WrapCode returns an error with err as its parent and the given code and reason.
+//
+// The message, fields and stack are inherited the same way Wrap inherits them.
+// If err is nil then WrapCode returns nil.
+func WrapCode(err error, code Code, reason string) Error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, code, reason, "", true)
+}
+
+// the stack skip is fixed: callers must be Wrap, Wrapf or WrapCode
+func wrap(err error, code Code, reason, message string, recode bool) Error {
+	var (
+		ae *AError
+		c  Code
+	)
+	switch {
+	case errors.As(err, &ae):
+		c = ae.code
+		if !recode {
+			reason = ae.reason
+		} else {
+			message = ae.message
+		}
+	case errors.As(err, &c):
+	default:
+		c = ErrUnknown
+		if !recode {
+			message = message + ": " + err.Error()
+		}
+	}
+	if recode {
+		c = code
+	}
+
+	e := newAError(c, reason)
+	if message != "" {
+		e.WithMessage(message)
+	}
+	e.addParent(err)
+	if ae != nil {
+		// keep the identifier so the error can still be correlated
+		e.id = append(e.id[:0], ae.id...)
+		for _, f := range ae.fields {
+			e.setField(f.key, f.value)
+		}
+		if !recode {
+			e.httpCode, e.grpcCode = ae.httpCode, ae.grpcCode
+		}
+		e.messageKey = ae.messageKey
+		e.messageArgs = append(e.messageArgs[:0], ae.messageArgs...)
+		// the details are cloned so that adding to them does not change the ones of ae
+		for _, d := range ae.details {
+			e.details = append(e.details, proto.Clone(d))
+		}
+	}
+	if !e.hasStack() {
+		if from := stackOf(err); from != nil {
+			e.inheritStack(from)
+		}
+	}
+	if !e.hasStack() {
+		e.captureStack(2, nil)
+	}
+	return e.Err()
+}